@@ -10,9 +10,11 @@ require (
 	github.com/sirupsen/logrus v1.9.4
 	github.com/suzuki-shunsuke/go-findconfig v1.2.0
 	github.com/urfave/cli/v2 v2.27.7
+	github.com/yuin/goldmark v1.7.13
 	gitlab.com/gitlab-org/api/client-go v1.41.0
 	go.uber.org/mock v0.6.0
 	gopkg.in/yaml.v2 v2.4.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
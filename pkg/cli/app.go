@@ -14,6 +14,40 @@ func (flags *LDFlags) AppVersion() string {
 	return flags.Version + " (" + flags.Commit + ")"
 }
 
+func inputFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:  "input-file",
+			Usage: "read the output of the command executed in advance from the file instead of running the command. '-' means the standard input",
+		},
+		&cli.IntFlag{
+			Name:  "exit-code",
+			Usage: "the exit code of the command executed in advance. This is used with --input-file",
+		},
+	}
+}
+
+func commentFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.IntFlag{
+			Name:  "max-code-length",
+			Usage: "the maximum length of the code block in the comment. The middle of the longer code block is omitted",
+		},
+		&cli.BoolFlag{
+			Name:  "split-comment",
+			Usage: "split the comment which exceeds the maximum length of GitLab note into multiple notes instead of omitting the content",
+		},
+		&cli.StringFlag{
+			Name:  "full-output",
+			Usage: "upload the full output of the command and link it from the comment instead of embedding it. snippet or artifact",
+		},
+		&cli.StringFlag{
+			Name:  "full-output-path",
+			Usage: "the path of the file which the full output is written to with --full-output artifact. It has to be relative to the project directory",
+		},
+	}
+}
+
 func New(flags *LDFlags) *cli.App {
 	app := cli.NewApp()
 	app.Name = "tfcmt-gitlab"
@@ -28,13 +62,14 @@ func New(flags *LDFlags) *cli.App {
 		&cli.IntFlag{Name: "mr", Usage: "merge request number"},
 		&cli.StringFlag{Name: "config", Usage: "config path"},
 		&cli.StringSliceFlag{Name: "var", Usage: "template variables. The format of value is '<name>:<value>'"},
+		&cli.BoolFlag{Name: "dry-run", Usage: "print the comment and the label operations to stdout instead of posting them to GitLab"},
 	}
 	app.Commands = []*cli.Command{
 		{
 			Name:   "plan",
 			Usage:  "Run terraform plan and post a comment to GitHub commit or pull request",
 			Action: cmdPlan,
-			Flags: []cli.Flag{
+			Flags: append([]cli.Flag{
 				&cli.BoolFlag{
 					Name:  "patch",
 					Usage: "update an existing comment instead of creating a new comment. If there is no existing comment, a new comment is created.",
@@ -43,12 +78,150 @@ func New(flags *LDFlags) *cli.App {
 					Name:  "skip-no-changes",
 					Usage: "If there is no change tfcmt updates a label but doesn't post a comment",
 				},
-			},
+				&cli.BoolFlag{
+					Name:  "discussion",
+					Usage: "post the result as a resolvable discussion instead of a note",
+				},
+				&cli.StringFlag{
+					Name:  "outdated-comment",
+					Usage: "hide or delete the older comments of the same target after posting a new comment. hide or delete",
+				},
+				&cli.StringFlag{
+					Name:  "terraform-report",
+					Usage: "write the report for the Terraform widget of merge requests (artifacts:reports:terraform) to the file",
+				},
+				&cli.BoolFlag{
+					Name:  "detailed-exitcode",
+					Usage: "treat the exit code 2 as the plan has changes like `terraform plan -detailed-exitcode`. This is enabled automatically if the command has -detailed-exitcode",
+				},
+				&cli.StringFlag{
+					Name:  "plan-json",
+					Usage: "parse the JSON representation of the plan (the output of `terraform show -json`) instead of the command output",
+				},
+				&cli.StringFlag{
+					Name:  "plan-file",
+					Usage: "parse the saved plan file via `terraform show -json` instead of the command output",
+				},
+				&cli.BoolFlag{
+					Name:  "terragrunt",
+					Usage: "parse the output of `terragrunt run-all plan` into the results of the modules. This is enabled automatically if the command is terragrunt run-all",
+				},
+			}, append(inputFlags(), commentFlags()...)...),
+		},
+		{
+			Name:   "plan-all",
+			Usage:  "Run terraform plan in multiple directories and post one comment which aggregates the results",
+			Action: cmdPlanAll,
+			Flags: append([]cli.Flag{
+				&cli.StringSliceFlag{
+					Name:     "dir",
+					Usage:    "the working directory of terraform. Glob patterns are expanded. This can be specified multiple times",
+					Required: true,
+				},
+				&cli.IntFlag{
+					Name:  "concurrency",
+					Usage: "the maximum number of terraform commands run at the same time",
+					Value: 4, //nolint:gomnd
+				},
+				&cli.BoolFlag{
+					Name:  "skip-no-changes",
+					Usage: "If there is no change in all directories tfcmt updates a label but doesn't post a comment",
+				},
+				&cli.StringFlag{
+					Name:  "terraform-report",
+					Usage: "write the report for the Terraform widget of merge requests (artifacts:reports:terraform) to the file",
+				},
+				&cli.BoolFlag{
+					Name:  "detailed-exitcode",
+					Usage: "treat the exit code 2 as the plan has changes like `terraform plan -detailed-exitcode`. This is enabled automatically if the command has -detailed-exitcode",
+				},
+				&cli.StringFlag{
+					Name:  "plan-file",
+					Usage: "parse the saved plan file in each directory via `terraform show -json` instead of the command output",
+				},
+			}, commentFlags()...),
 		},
 		{
 			Name:   "apply",
 			Usage:  "Run terraform apply and post a comment to GitHub commit or pull request",
 			Action: cmdApply,
+			Flags: append(append([]cli.Flag{
+				&cli.BoolFlag{
+					Name:  "link-plan",
+					Usage: "link the result to the last plan comment of the same target and compare the applied changes with the plan",
+				},
+			}, inputFlags()...), commentFlags()...),
+		},
+		{
+			Name:   "drift",
+			Usage:  "Run terraform plan -refresh-only and open a GitLab issue per target while the drift is detected",
+			Action: cmdDrift,
+			Flags: append([]cli.Flag{
+				&cli.StringSliceFlag{
+					Name:  "issue-label",
+					Usage: "the label which is added to the drift issue. This can be specified multiple times",
+				},
+				&cli.IntSliceFlag{
+					Name:  "assignee-id",
+					Usage: "the ID of the user who is assigned to the drift issue. This can be specified multiple times",
+				},
+			}, inputFlags()...),
+		},
+		{
+			Name:  "config",
+			Usage: "Manage the configuration file",
+			Subcommands: []*cli.Command{
+				{
+					Name:      "validate",
+					Usage:     "Validate the configuration file strictly. Unknown keys, invalid templates, label colors and complement entries are reported",
+					ArgsUsage: "[config path]",
+					Action:    cmdConfigValidate,
+				},
+				{
+					Name:   "schema",
+					Usage:  "Output the JSON Schema of the configuration file",
+					Action: cmdConfigSchema,
+				},
+			},
+		},
+		{
+			Name:  "template",
+			Usage: "Manage templates",
+			Subcommands: []*cli.Command{
+				{
+					Name:   "render",
+					Usage:  "Render the comment from the output of terraform command saved in a file without posting it",
+					Action: cmdTemplateRender,
+					Flags: []cli.Flag{
+						&cli.StringFlag{
+							Name:     "input-file",
+							Usage:    "the output of terraform command. '-' means the standard input",
+							Required: true,
+						},
+						&cli.IntFlag{
+							Name:  "exit-code",
+							Usage: "the exit code of terraform command",
+						},
+						&cli.StringFlag{
+							Name:  "command",
+							Usage: "the command whose template is rendered. plan, apply or drift",
+							Value: "plan",
+						},
+						&cli.StringFlag{
+							Name:  "plan-json",
+							Usage: "the JSON representation of the plan (the output of terraform show -json). This is used with --command plan",
+						},
+						&cli.BoolFlag{
+							Name:  "terragrunt",
+							Usage: "parse the output of `terragrunt run-all plan` into the results of the modules. This is used with --command plan",
+						},
+						&cli.BoolFlag{
+							Name:  "html",
+							Usage: "render the comment as a HTML page",
+						},
+					},
+				},
+			},
 		},
 		{
 			Name:  "version",
@@ -29,6 +29,8 @@ func cmdApply(ctx *cli.Context) error {
 		Parser:             terraform.NewApplyParser(),
 		Template:           terraform.NewApplyTemplate(cfg.Terraform.Apply.Template),
 		ParseErrorTemplate: terraform.NewApplyParseErrorTemplate(cfg.Terraform.Apply.WhenParseError.Template),
+		InputFile:          ctx.String("input-file"),
+		ExitCode:           ctx.Int("exit-code"),
 	}
 
 	args := ctx.Args()
@@ -1,8 +1,15 @@
 package cli
 
 import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
 	"github.com/hirosassa/tfcmt-gitlab/pkg/config"
 	"github.com/urfave/cli/v2"
+	"gopkg.in/yaml.v2"
 )
 
 func newConfig(ctx *cli.Context) (config.Config, error) {
@@ -18,3 +25,82 @@ func newConfig(ctx *cli.Context) (config.Config, error) {
 	}
 	return cfg, nil
 }
+
+func cmdConfigValidate(ctx *cli.Context) error {
+	setLogLevel(ctx.String("log-level"))
+
+	file := ctx.String("config")
+	if ctx.Args().Present() {
+		file = ctx.Args().First()
+	}
+	cfg := config.Config{}
+	confPath, err := cfg.Find(file)
+	if err != nil {
+		return err
+	}
+	if confPath == "" {
+		return errors.New("config for tfcmt is not found at all")
+	}
+
+	count, err := validateConfigFile(ctx.App.ErrWriter, confPath, map[string]struct{}{})
+	if err != nil {
+		return err
+	}
+	if count != 0 {
+		return fmt.Errorf("%d problem(s) are found in %s", count, confPath)
+	}
+	// check the config files can be merged
+	return cfg.LoadFile(confPath)
+}
+
+// validateConfigFile lints the config file and the config files which it extends, and prints the problems.
+// It returns the number of the problems.
+func validateConfigFile(w io.Writer, path string, visited map[string]struct{}) (int, error) {
+	if _, ok := visited[path]; ok {
+		return 0, nil
+	}
+	visited[path] = struct{}{}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return 0, fmt.Errorf("read a config file %s: %w", path, err)
+	}
+	problems := config.Lint(raw)
+	for _, p := range problems {
+		fmt.Fprintf(w, "%s:%s\n", path, p)
+	}
+	count := len(problems)
+
+	// the config files which this file extends are validated even if this file has problems,
+	// so only extends is decoded
+	cfg := struct {
+		Extends config.Extends `yaml:"extends"`
+	}{}
+	if err := yaml.Unmarshal(raw, &cfg); err != nil {
+		if count != 0 {
+			// the problem has already been reported
+			return count, nil
+		}
+		return 0, fmt.Errorf("parse a config file %s: %w", path, err)
+	}
+	for _, p := range cfg.Extends {
+		if !filepath.IsAbs(p) {
+			p = filepath.Join(filepath.Dir(path), p)
+		}
+		c, err := validateConfigFile(w, p, visited)
+		if err != nil {
+			return 0, err
+		}
+		count += c
+	}
+	return count, nil
+}
+
+func cmdConfigSchema(ctx *cli.Context) error {
+	b, err := config.SchemaJSON()
+	if err != nil {
+		return err
+	}
+	_, err = ctx.App.Writer.Write(b)
+	return err
+}
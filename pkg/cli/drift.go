@@ -0,0 +1,42 @@
+package cli
+
+import (
+	"github.com/hirosassa/tfcmt-gitlab/pkg/controller"
+	"github.com/hirosassa/tfcmt-gitlab/pkg/terraform"
+	"github.com/urfave/cli/v2"
+)
+
+func cmdDrift(ctx *cli.Context) error {
+	logLevel := ctx.String("log-level")
+	setLogLevel(logLevel)
+
+	cfg, err := newConfig(ctx)
+	if err != nil {
+		return err
+	}
+
+	if logLevel == "" {
+		logLevel = cfg.Log.Level
+		setLogLevel(logLevel)
+	}
+
+	if err := parseOpts(ctx, &cfg); err != nil {
+		return err
+	}
+
+	t := &controller.Controller{
+		Config:             cfg,
+		Parser:             terraform.NewDriftParser(),
+		Template:           terraform.NewDriftTemplate(cfg.Terraform.Drift.Template),
+		ParseErrorTemplate: terraform.NewPlanParseErrorTemplate(cfg.Terraform.Plan.WhenParseError.Template),
+		InputFile:          ctx.String("input-file"),
+		ExitCode:           ctx.Int("exit-code"),
+	}
+
+	args := ctx.Args()
+
+	return t.Run(ctx.Context, controller.Command{
+		Cmd:  args.First(),
+		Args: args.Tail(),
+	})
+}
@@ -1,6 +1,11 @@
 package cli
 
 import (
+	"errors"
+	"path/filepath"
+	"slices"
+	"strings"
+
 	"github.com/hirosassa/tfcmt-gitlab/pkg/controller"
 	"github.com/hirosassa/tfcmt-gitlab/pkg/terraform"
 	"github.com/urfave/cli/v2"
@@ -23,16 +28,49 @@ func cmdPlan(ctx *cli.Context) error {
 		return err
 	}
 
+	args := ctx.Args()
+	if slices.ContainsFunc(args.Slice(), isDetailedExitCodeOption) {
+		cfg.Terraform.Plan.DetailedExitCode = true
+	}
+
+	var parser terraform.Parser = terraform.NewPlanParser()
+	if ctx.Bool("terragrunt") || isTerragruntRunAll(args.Slice()) {
+		parser = terraform.NewTerragruntPlanParser()
+	}
+	planJSONFile := ctx.String("plan-json")
+	planFile := ctx.String("plan-file")
+	if planJSONFile != "" && planFile != "" {
+		return errors.New("--plan-json and --plan-file can't be specified at the same time")
+	}
+	if planJSONFile != "" || planFile != "" {
+		parser = terraform.NewJSONPlanParser()
+	}
+
 	t := &controller.Controller{
 		Config:             cfg,
-		Parser:             terraform.NewPlanParser(),
+		Parser:             parser,
 		Template:           terraform.NewPlanTemplate(cfg.Terraform.Plan.Template),
 		ParseErrorTemplate: terraform.NewPlanParseErrorTemplate(cfg.Terraform.Plan.WhenParseError.Template),
+		PlanJSONFile:       planJSONFile,
+		PlanFile:           planFile,
+		InputFile:          ctx.String("input-file"),
+		ExitCode:           ctx.Int("exit-code"),
 	}
-	args := ctx.Args()
 
 	return t.Run(ctx.Context, controller.Command{
 		Cmd:  args.First(),
 		Args: args.Tail(),
 	})
 }
+
+// isTerragruntRunAll returns true if the command runs terragrunt for all modules such as `terragrunt run-all plan` and `terragrunt run --all plan`
+func isTerragruntRunAll(args []string) bool {
+	if len(args) == 0 || filepath.Base(args[0]) != "terragrunt" {
+		return false
+	}
+	return slices.Contains(args[1:], "run-all") || slices.Contains(args[1:], "--all")
+}
+
+func isDetailedExitCodeOption(arg string) bool {
+	return strings.TrimLeft(arg, "-") == "detailed-exitcode"
+}
@@ -0,0 +1,51 @@
+package cli
+
+import (
+	"slices"
+
+	"github.com/hirosassa/tfcmt-gitlab/pkg/controller"
+	"github.com/hirosassa/tfcmt-gitlab/pkg/terraform"
+	"github.com/urfave/cli/v2"
+)
+
+func cmdPlanAll(ctx *cli.Context) error {
+	logLevel := ctx.String("log-level")
+	setLogLevel(logLevel)
+
+	cfg, err := newConfig(ctx)
+	if err != nil {
+		return err
+	}
+	if logLevel == "" {
+		logLevel = cfg.Log.Level
+		setLogLevel(logLevel)
+	}
+
+	if err := parseOpts(ctx, &cfg); err != nil {
+		return err
+	}
+
+	args := ctx.Args()
+	if slices.ContainsFunc(args.Slice(), isDetailedExitCodeOption) {
+		cfg.Terraform.Plan.DetailedExitCode = true
+	}
+
+	var parser terraform.Parser = terraform.NewPlanParser()
+	planFile := ctx.String("plan-file")
+	if planFile != "" {
+		parser = terraform.NewJSONPlanParser()
+	}
+
+	t := &controller.Controller{
+		Config:             cfg,
+		Parser:             parser,
+		Template:           terraform.NewPlanAllTemplate(cfg.Terraform.PlanAll.Template),
+		ParseErrorTemplate: terraform.NewPlanParseErrorTemplate(cfg.Terraform.Plan.WhenParseError.Template),
+		PlanFile:           planFile,
+	}
+
+	return t.RunAll(ctx.Context, controller.Command{
+		Cmd:  args.First(),
+		Args: args.Tail(),
+	}, ctx.StringSlice("dir"), ctx.Int("concurrency"))
+}
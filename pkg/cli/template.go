@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/hirosassa/tfcmt-gitlab/pkg/controller"
+	"github.com/hirosassa/tfcmt-gitlab/pkg/terraform"
+	"github.com/urfave/cli/v2"
+)
+
+func cmdTemplateRender(ctx *cli.Context) error {
+	logLevel := ctx.String("log-level")
+	setLogLevel(logLevel)
+
+	cfg, err := newConfig(ctx)
+	if err != nil {
+		return err
+	}
+
+	if logLevel == "" {
+		logLevel = cfg.Log.Level
+		setLogLevel(logLevel)
+	}
+
+	if err := parseOpts(ctx, &cfg); err != nil {
+		return err
+	}
+
+	t := &controller.Controller{
+		Config:       cfg,
+		PlanJSONFile: ctx.String("plan-json"),
+		InputFile:    ctx.String("input-file"),
+		ExitCode:     ctx.Int("exit-code"),
+	}
+	switch command := ctx.String("command"); command {
+	case "plan":
+		t.Parser = terraform.NewPlanParser()
+		if ctx.Bool("terragrunt") {
+			t.Parser = terraform.NewTerragruntPlanParser()
+		}
+		if t.PlanJSONFile != "" {
+			t.Parser = terraform.NewJSONPlanParser()
+		}
+		t.Template = terraform.NewPlanTemplate(cfg.Terraform.Plan.Template)
+		t.ParseErrorTemplate = terraform.NewPlanParseErrorTemplate(cfg.Terraform.Plan.WhenParseError.Template)
+	case "apply":
+		t.Parser = terraform.NewApplyParser()
+		t.Template = terraform.NewApplyTemplate(cfg.Terraform.Apply.Template)
+		t.ParseErrorTemplate = terraform.NewApplyParseErrorTemplate(cfg.Terraform.Apply.WhenParseError.Template)
+	case "drift":
+		t.Parser = terraform.NewDriftParser()
+		t.Template = terraform.NewDriftTemplate(cfg.Terraform.Drift.Template)
+		t.ParseErrorTemplate = terraform.NewPlanParseErrorTemplate(cfg.Terraform.Plan.WhenParseError.Template)
+	default:
+		return fmt.Errorf("command must be plan, apply or drift: %s", command)
+	}
+
+	body, err := t.Render(ctx.Context)
+	if err != nil {
+		return err
+	}
+	if ctx.Bool("html") {
+		body, err = controller.RenderHTML(body)
+		if err != nil {
+			return err
+		}
+	}
+	fmt.Fprint(ctx.App.Writer, body)
+	return nil
+}
@@ -36,20 +36,72 @@ func parseOpts(ctx *cli.Context, cfg *config.Config) error {
 		cfg.CI.MRNumber = mr
 	}
 
+	if ctx.IsSet("dry-run") {
+		cfg.DryRun = ctx.Bool("dry-run")
+	}
+
 	if ctx.IsSet("patch") {
 		cfg.PlanPatch = ctx.Bool("patch")
 	}
 
+	if ctx.IsSet("link-plan") {
+		cfg.Terraform.Apply.LinkPlan = ctx.Bool("link-plan")
+	}
+
+	if ctx.IsSet("discussion") {
+		cfg.Terraform.Plan.Discussion.Enabled = ctx.Bool("discussion")
+	}
+
+	if outdated := ctx.String("outdated-comment"); outdated != "" {
+		cfg.Terraform.Plan.OutdatedComment = outdated
+	}
+
+	if ctx.IsSet("max-code-length") {
+		cfg.Terraform.MaxCodeLength = ctx.Int("max-code-length")
+	}
+
+	if ctx.IsSet("split-comment") {
+		cfg.Terraform.SplitComment = ctx.Bool("split-comment")
+	}
+
+	if fullOutput := ctx.String("full-output"); fullOutput != "" {
+		cfg.Terraform.FullOutput.Type = fullOutput
+	}
+
+	if path := ctx.String("full-output-path"); path != "" {
+		cfg.Terraform.FullOutput.Path = path
+	}
+
+	if path := ctx.String("terraform-report"); path != "" {
+		cfg.Terraform.Plan.TerraformReportPath = path
+	}
+
+	if labels := ctx.StringSlice("issue-label"); len(labels) != 0 {
+		cfg.Terraform.Drift.Labels = labels
+	}
+
+	if ids := ctx.IntSlice("assignee-id"); len(ids) != 0 {
+		cfg.Terraform.Drift.AssigneeIDs = ids
+	}
+
 	if buildURL := ctx.String("build-url"); buildURL != "" {
 		cfg.CI.Link = buildURL
 	}
 
+	if ctx.IsSet("detailed-exitcode") {
+		cfg.Terraform.Plan.DetailedExitCode = ctx.Bool("detailed-exitcode")
+	}
+
 	if ctx.IsSet("skip-no-changes") {
 		cfg.Terraform.Plan.WhenNoChanges.DisableComment = ctx.Bool("skip-no-changes")
 	}
 
+	// the variables given by --var override the ones in the config file
 	vars := ctx.StringSlice("var")
-	vm := make(map[string]string, len(vars))
+	vm := make(map[string]string, len(cfg.Vars)+len(vars))
+	for k, v := range cfg.Vars {
+		vm[k] = v
+	}
 	if err := parseVarOpts(vars, vm); err != nil {
 		return err
 	}
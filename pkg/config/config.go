@@ -11,16 +11,60 @@ import (
 
 // Config is for tfcmt config structure
 type Config struct {
-	CI               CI `yaml:"-"`
-	Terraform        Terraform
-	Vars             map[string]string `yaml:"-"`
-	EmbeddedVarNames []string          `yaml:"embedded_var_names"`
+	// Extends is the paths of the config files which this config file extends. They're relative to this config file
+	Extends   Extends
+	CI        CI `yaml:"-"`
+	Terraform Terraform
+	// Vars are the variables available in templates. They're overridden by --var
+	Vars             map[string]string
+	EmbeddedVarNames []string `yaml:"embedded_var_names"`
 	Templates        map[string]string
 	Log              Log
 	BaseURL          string     `yaml:"base_url"`
 	GitLabToken      string     `yaml:"-"`
 	Complement       Complement `yaml:"ci"`
 	PlanPatch        bool       `yaml:"plan_patch"`
+	Notifiers        []Notifier
+	Policy           Policy
+	// DryRun means nothing is written to GitLab and the webhooks. The comments and the label operations are printed to stdout instead
+	DryRun bool `yaml:"dry_run"`
+}
+
+// Notifier is a configuration of the notification sent in addition to GitLab
+type Notifier struct {
+	// Type is either slack or webhook
+	Type string `jsonschema:"enum=slack,webhook"`
+	// URL is the URL of the webhook. "$NAME" means the environment variable NAME
+	URL string
+	// Template renders the Slack message or the body of the webhook
+	Template string
+	// Headers are added to the webhook request. "$NAME" in values means the environment variable NAME
+	Headers map[string]string
+	// OnlyFailure means the notification is sent only when the command fails
+	OnlyFailure bool `yaml:"only_failure"`
+	// Commands limits the commands which send the notification, e.g. [apply]. If it's empty, all commands send the notification
+	Commands []string
+}
+
+// Policy is a configuration of the guardrails evaluated against the plan result.
+// If the plan violates any rule, the violations are rendered in the comment, the label is added,
+// and the command exits with non-zero.
+type Policy struct {
+	Rules []PolicyRule
+	// Label is added to the merge request when the plan violates the policy. The default is "policy-violation"
+	Label string
+	Color string `yaml:"label_color" jsonschema:"color"`
+}
+
+// PolicyRule is a rule of the changes which aren't allowed
+type PolicyRule struct {
+	Name string
+	// Actions are the actions which the rule matches. If it's empty, the rule matches all actions
+	Actions []string `jsonschema:"enum=create,update,delete,replace"`
+	// Resources are the globs of the resource addresses such as aws_db_instance.*. If it's empty, the rule matches all resources
+	Resources []string
+	// MaxChanges is the maximum number of the changes matched by the rule. If it's zero, any matched change violates the rule
+	MaxChanges int `yaml:"max_changes"`
 }
 
 type CI struct {
@@ -30,18 +74,68 @@ type CI struct {
 	SHA       string
 	Link      string
 	MRNumber  int
+	// PipelineID is the ID of the CI pipeline. It's embedded in the comment as metadata
+	PipelineID string
+	// PipelineSHA is the commit SHA which the CI pipeline runs for.
+	// It can be different from SHA in merged results pipelines
+	PipelineSHA string
+	// JobName is the name of the CI job. It's written in the report for the Terraform widget
+	JobName string
 }
 
 type Log struct {
-	Level string
+	Level string `jsonschema:"enum=panic,fatal,error,warn,warning,info,debug,trace"`
 	// Format string
 }
 
 // Terraform represents terraform configurations
 type Terraform struct {
+	// Tool is the tool which runs plan and apply. If it's empty, it's detected from the command or the output
+	Tool         string `jsonschema:"enum=terraform,opentofu"`
 	Plan         Plan
+	PlanAll      PlanAll `yaml:"plan_all"`
 	Apply        Apply
 	UseRawOutput bool `yaml:"use_raw_output"`
+	// MaxCodeLength is the maximum length of the code block in the comment. The middle of the longer code block is omitted
+	MaxCodeLength int `yaml:"max_code_length"`
+	// SplitComment means the comment which exceeds the maximum length of GitLab note is split into multiple notes
+	SplitComment bool       `yaml:"split_comment"`
+	FullOutput   FullOutput `yaml:"full_output"`
+	// CommitStatus sets a commit status per target so that the result of each target is shown in the pipeline
+	CommitStatus CommitStatus `yaml:"commit_status"`
+	// Drift is a configuration of the drift command which manages an issue per target
+	Drift Drift
+}
+
+// Drift is a configuration of the drift command.
+// An issue is opened while the drift is detected and closed when the drift disappears
+type Drift struct {
+	// Template is the template of the description of the issue
+	Template string
+	// Title is the template of the title of the issue. The default is "Drift detected" or "Drift detected (<target>)"
+	Title string
+	// Labels are added to the issue
+	Labels []string
+	// AssigneeIDs are the IDs of the users who are assigned to the issue
+	AssigneeIDs []int `yaml:"assignee_ids"`
+}
+
+// CommitStatus is a configuration of the commit status which is set per target
+type CommitStatus struct {
+	Enabled bool
+	// Name is a template of the name of the commit status. It's rendered with .Command, .Target and .Vars.
+	// The default is "tfcmt/<command>/<target>" or "tfcmt/<command>"
+	Name string
+	// DestroyState is the state of the commit status when the plan destroys resources. The default is skipped
+	DestroyState string `yaml:"destroy_state" jsonschema:"enum=pending,running,success,failed,canceled,skipped"`
+}
+
+// FullOutput is a configuration to upload the full output of the command outside of the comment and link it from the comment
+type FullOutput struct {
+	// Type is either snippet or artifact
+	Type string `jsonschema:"enum=snippet,artifact"`
+	// Path is the path of the file written with the type artifact. It has to be relative to the project directory
+	Path string
 }
 
 // Plan is a terraform plan config
@@ -52,32 +146,88 @@ type Plan struct {
 	WhenNoChanges       WhenNoChanges       `yaml:"when_no_changes"`
 	WhenPlanError       WhenPlanError       `yaml:"when_plan_error"`
 	WhenParseError      WhenParseError      `yaml:"when_parse_error"`
+	WhenMoved           WhenMoved           `yaml:"when_moved"`
+	WhenImported        WhenImported        `yaml:"when_imported"`
+	WhenForgotten       WhenForgotten       `yaml:"when_forgotten"`
 	DisableLabel        bool                `yaml:"disable_label"`
+	// DetailedExitCode means terraform plan is run with `-detailed-exitcode`
+	DetailedExitCode bool `yaml:"detailed_exitcode"`
+	// ChangesExitCode is the exit code of tfcmt when the plan has changes with DetailedExitCode
+	ChangesExitCode int `yaml:"changes_exit_code"`
+	Discussion      Discussion
+	// OutdatedComment is the strategy for the older comments of the same target. "hide" or "delete"
+	OutdatedComment string `yaml:"outdated_comment" jsonschema:"enum=hide,delete"`
+	// TerraformReportPath is the path of the report for the Terraform widget of merge requests (artifacts:reports:terraform)
+	TerraformReportPath string `yaml:"terraform_report_path"`
+}
+
+// Discussion is a configuration to post the plan result as a resolvable discussion of the merge request
+type Discussion struct {
+	Enabled     bool
+	AutoResolve bool `yaml:"auto_resolve"`
 }
 
 // WhenAddOrUpdateOnly is a configuration to notify the plan result contains new or updated in place resources
 type WhenAddOrUpdateOnly struct {
 	Label string
-	Color string `yaml:"label_color"`
+	Color string `yaml:"label_color" jsonschema:"color"`
 }
 
 // WhenDestroy is a configuration to notify the plan result contains destroy operation
 type WhenDestroy struct {
 	Label string
-	Color string `yaml:"label_color"`
+	Color string `yaml:"label_color" jsonschema:"color"`
+	// ApprovalRule requires the approvals of the merge request while the plan destroys resources
+	ApprovalRule ApprovalRule `yaml:"approval_rule"`
+}
+
+// ApprovalRule is a configuration of the merge request approval rule which is created when the plan destroys resources.
+// The rule is deleted when a later plan doesn't destroy anything
+type ApprovalRule struct {
+	// Name is the name of the rule. It's a template. The default is "tfcmt destroy" or "tfcmt destroy (<target>)"
+	Name string
+	// ApprovalsRequired is the number of the required approvals. The default is 1
+	ApprovalsRequired int `yaml:"approvals_required"`
+	// UserIDs are the IDs of the users who can approve
+	UserIDs []int `yaml:"user_ids"`
+	// GroupIDs are the IDs of the groups whose members can approve
+	GroupIDs []int `yaml:"group_ids"`
+}
+
+// Enabled returns true if the approval rule is configured
+func (r *ApprovalRule) Enabled() bool {
+	return r.Name != "" || r.ApprovalsRequired != 0 || len(r.UserIDs) != 0 || len(r.GroupIDs) != 0
 }
 
 // WhenNoChanges is a configuration to add a label when the plan result contains no change
 type WhenNoChanges struct {
 	Label          string
-	Color          string `yaml:"label_color"`
+	Color          string `yaml:"label_color" jsonschema:"color"`
 	DisableComment bool   `yaml:"disable_comment"`
 }
 
 // WhenPlanError is a configuration to notify the plan result returns an error
 type WhenPlanError struct {
 	Label string
-	Color string `yaml:"label_color"`
+	Color string `yaml:"label_color" jsonschema:"color"`
+}
+
+// WhenMoved is a configuration to add a label when the plan result contains moved resources
+type WhenMoved struct {
+	Label string
+	Color string `yaml:"label_color" jsonschema:"color"`
+}
+
+// WhenImported is a configuration to add a label when the plan result contains imported resources
+type WhenImported struct {
+	Label string
+	Color string `yaml:"label_color" jsonschema:"color"`
+}
+
+// WhenForgotten is a configuration to add a label when the plan result contains resources removed from the state
+type WhenForgotten struct {
+	Label string
+	Color string `yaml:"label_color" jsonschema:"color"`
 }
 
 // WhenParseError is a configuration to notify the plan result returns an error
@@ -85,29 +235,126 @@ type WhenParseError struct {
 	Template string
 }
 
+// PlanAll is a config of terraform plan for multiple directories
+type PlanAll struct {
+	Template string
+}
+
 // Apply is a terraform apply config
 type Apply struct {
 	Template       string
 	WhenParseError WhenParseError `yaml:"when_parse_error"`
+	// LinkPlan means the apply result links to the last plan comment of the same target and is compared with the plan
+	LinkPlan bool `yaml:"link_plan"`
 }
 
-// LoadFile binds the config file to Config structure
+// LoadFile binds the config file to Config structure.
+// If the config file extends other config files, they're merged into it
 func (cfg *Config) LoadFile(path string) error {
 	if _, err := os.Stat(path); err != nil {
 		return fmt.Errorf("%s: no config file", path)
 	}
 	raw, _ := os.ReadFile(path)
-	return yaml.Unmarshal(raw, cfg)
+	if err := yaml.Unmarshal(raw, cfg); err != nil {
+		return err
+	}
+	if len(cfg.Extends) == 0 {
+		return nil
+	}
+
+	m, err := loadExtended(path, nil)
+	if err != nil {
+		return err
+	}
+	merged, err := yaml.Marshal(m)
+	if err != nil {
+		return fmt.Errorf("marshal the merged config: %w", err)
+	}
+	*cfg = Config{}
+	return yaml.Unmarshal(merged, cfg)
 }
 
 // Validate validates config file
 func (cfg *Config) Validate() error {
-	if cfg.CI.NameSpace == "" {
-		return errors.New("namespace is missing")
+	// In dry run, nothing is posted to GitLab so the project and the merge request aren't required
+	if !cfg.DryRun {
+		if err := cfg.validateCI(); err != nil {
+			return err
+		}
 	}
+	return cfg.validateOptions()
+}
 
-	if cfg.CI.Project == "" {
-		return errors.New("project name is missing")
+// ValidateDrift validates the configuration of the drift command.
+// Unlike Validate, neither a merge request nor a commit is required because the drift is reported as an issue
+func (cfg *Config) ValidateDrift() error {
+	if !cfg.DryRun {
+		if err := cfg.validateProject(); err != nil {
+			return err
+		}
+	}
+	return cfg.validateOptions()
+}
+
+// validateOptions validates the options which don't depend on the CI environment
+func (cfg *Config) validateOptions() error {
+	switch cfg.Terraform.Plan.OutdatedComment {
+	case "", "hide", "delete":
+	default:
+		return fmt.Errorf("outdated_comment must be either hide or delete: %s", cfg.Terraform.Plan.OutdatedComment)
+	}
+
+	switch cfg.Terraform.FullOutput.Type {
+	case "", "snippet", "artifact":
+	default:
+		return fmt.Errorf("full_output.type must be either snippet or artifact: %s", cfg.Terraform.FullOutput.Type)
+	}
+
+	switch cfg.Terraform.Tool {
+	case "", "terraform", "opentofu":
+	default:
+		return fmt.Errorf("terraform.tool must be either terraform or opentofu: %s", cfg.Terraform.Tool)
+	}
+
+	switch cfg.Terraform.CommitStatus.DestroyState {
+	case "", "pending", "running", "success", "failed", "canceled", "skipped":
+	default:
+		return fmt.Errorf("commit_status.destroy_state must be pending, running, success, failed, canceled or skipped: %s", cfg.Terraform.CommitStatus.DestroyState)
+	}
+
+	if n := cfg.Terraform.Plan.WhenDestroy.ApprovalRule.ApprovalsRequired; n < 0 {
+		return fmt.Errorf("when_destroy.approval_rule.approvals_required must not be negative: %d", n)
+	}
+
+	for i, rule := range cfg.Policy.Rules {
+		for _, action := range rule.Actions {
+			switch action {
+			case "create", "update", "delete", "replace":
+			default:
+				return fmt.Errorf("policy.rules[%d].actions must be create, update, delete or replace: %s", i, action)
+			}
+		}
+		if rule.MaxChanges < 0 {
+			return fmt.Errorf("policy.rules[%d].max_changes must not be negative: %d", i, rule.MaxChanges)
+		}
+	}
+
+	for i, n := range cfg.Notifiers {
+		switch n.Type {
+		case "slack", "webhook":
+		default:
+			return fmt.Errorf("notifiers[%d].type must be either slack or webhook: %s", i, n.Type)
+		}
+		if n.URL == "" {
+			return fmt.Errorf("notifiers[%d].url is missing", i)
+		}
+	}
+	return nil
+}
+
+func (cfg *Config) validateCI() error {
+	if err := cfg.validateProject(); err != nil {
+		return err
 	}
 
 	if cfg.CI.SHA == "" && cfg.CI.MRNumber <= 0 {
@@ -116,6 +363,17 @@ func (cfg *Config) Validate() error {
 	return nil
 }
 
+func (cfg *Config) validateProject() error {
+	if cfg.CI.NameSpace == "" {
+		return errors.New("namespace is missing")
+	}
+
+	if cfg.CI.Project == "" {
+		return errors.New("project name is missing")
+	}
+	return nil
+}
+
 // Find returns config path
 func (cfg *Config) Find(file string) (string, error) {
 	if file != "" {
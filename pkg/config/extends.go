@@ -0,0 +1,101 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Extends is a list of the paths of the configuration files which a configuration file extends.
+// It's either a path or a list of paths in YAML.
+type Extends []string
+
+// UnmarshalYAML accepts either a string or a list of strings
+func (e *Extends) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var path string
+	if err := unmarshal(&path); err == nil {
+		if path != "" {
+			*e = Extends{path}
+		}
+		return nil
+	}
+	var paths []string
+	if err := unmarshal(&paths); err != nil {
+		return errors.New("extends must be either a path or a list of paths")
+	}
+	*e = paths
+	return nil
+}
+
+// loadExtended reads the configuration file and merges the configuration files which it extends into it.
+// The paths in extends are relative to the configuration file. The later files override the earlier ones,
+// and the configuration file itself overrides all of them. Mappings are merged deeply, and the other values
+// including lists are overridden.
+// stack is the chain of the configuration files which extend path, and it's used to detect a cycle.
+func loadExtended(path string, stack []string) (map[interface{}]interface{}, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("get an absolute path of %s: %w", path, err)
+	}
+	for _, p := range stack {
+		if p == abs {
+			return nil, fmt.Errorf("config files extend each other: %s", strings.Join(append(stack, abs), " -> "))
+		}
+	}
+	stack = append(stack, abs)
+
+	raw, err := os.ReadFile(abs)
+	if err != nil {
+		return nil, fmt.Errorf("read a config file %s: %w", path, err)
+	}
+	m := map[interface{}]interface{}{}
+	if err := yaml.Unmarshal(raw, &m); err != nil {
+		return nil, fmt.Errorf("parse a config file %s: %w", path, err)
+	}
+
+	var extends Extends
+	if v, ok := m["extends"]; ok {
+		b, err := yaml.Marshal(v)
+		if err != nil {
+			return nil, fmt.Errorf("marshal extends of %s: %w", path, err)
+		}
+		if err := yaml.Unmarshal(b, &extends); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+
+	merged := map[interface{}]interface{}{}
+	for _, p := range extends {
+		if !filepath.IsAbs(p) {
+			p = filepath.Join(filepath.Dir(abs), p)
+		}
+		base, err := loadExtended(p, stack)
+		if err != nil {
+			return nil, err
+		}
+		mergeMap(merged, base)
+	}
+	mergeMap(merged, m)
+	return merged, nil
+}
+
+// mergeMap merges src into dst deeply. The values in src override the values in dst except for mappings
+func mergeMap(dst, src map[interface{}]interface{}) {
+	for k, v := range src {
+		srcMap, ok := v.(map[interface{}]interface{})
+		if !ok {
+			dst[k] = v
+			continue
+		}
+		dstMap, ok := dst[k].(map[interface{}]interface{})
+		if !ok {
+			dstMap = map[interface{}]interface{}{}
+		}
+		mergeMap(dstMap, srcMap)
+		dst[k] = dstMap
+	}
+}
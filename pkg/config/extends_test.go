@@ -0,0 +1,139 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		p := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil { //nolint:gomnd
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o600); err != nil { //nolint:gomnd
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestLoadFileExtends(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		name  string
+		files map[string]string
+		path  string
+		exp   func(cfg Config) any
+		want  any
+		err   string
+	}{
+		{
+			name: "the root config is overridden deeply",
+			files: map[string]string{
+				"tfcmt.yaml": `
+embedded_var_names: [target]
+templates:
+  plan_title: "## Plan Result"
+terraform:
+  plan:
+    when_destroy:
+      label: destroy
+      label_color: "#d93f0b"
+`,
+				"envs/prod/tfcmt.yaml": `
+extends: ../../tfcmt.yaml
+terraform:
+  plan:
+    when_destroy:
+      label_color: "#ff0000"
+`,
+			},
+			path: "envs/prod/tfcmt.yaml",
+			exp: func(cfg Config) any {
+				return []any{cfg.EmbeddedVarNames, cfg.Templates, cfg.Terraform.Plan.WhenDestroy}
+			},
+			want: []any{
+				[]string{"target"},
+				map[string]string{"plan_title": "## Plan Result"},
+				WhenDestroy{Label: "destroy", Color: "#ff0000"},
+			},
+		},
+		{
+			name: "a var is overridden",
+			files: map[string]string{
+				"tfcmt.yaml": `
+vars:
+  target: dev
+  owner: platform
+`,
+				"envs/prod/tfcmt.yaml": `
+extends: ../../tfcmt.yaml
+vars:
+  target: prod
+`,
+			},
+			path: "envs/prod/tfcmt.yaml",
+			exp: func(cfg Config) any {
+				return cfg.Vars
+			},
+			want: map[string]string{"target": "prod", "owner": "platform"},
+		},
+		{
+			name: "the later config files override the earlier ones",
+			files: map[string]string{
+				"base.yaml":   "embedded_var_names: [target]\nplan_patch: true\n",
+				"labels.yaml": "embedded_var_names: [env]\n",
+				"tfcmt.yaml":  "extends: [base.yaml, labels.yaml]\n",
+			},
+			path: "tfcmt.yaml",
+			exp: func(cfg Config) any {
+				return []any{cfg.EmbeddedVarNames, cfg.PlanPatch}
+			},
+			want: []any{[]string{"env"}, true},
+		},
+		{
+			name: "cycle",
+			files: map[string]string{
+				"a.yaml": "extends: b.yaml\n",
+				"b.yaml": "extends: a.yaml\n",
+			},
+			path: "a.yaml",
+			err:  "config files extend each other",
+		},
+		{
+			name: "missing file",
+			files: map[string]string{
+				"tfcmt.yaml": "extends: base.yaml\n",
+			},
+			path: "tfcmt.yaml",
+			err:  "read a config file",
+		},
+	}
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+			dir := t.TempDir()
+			writeFiles(t, dir, testCase.files)
+			cfg := Config{}
+			err := cfg.LoadFile(filepath.Join(dir, testCase.path))
+			if testCase.err != "" {
+				if err == nil || !strings.Contains(err.Error(), testCase.err) {
+					t.Fatalf("got error %v but want %q", err, testCase.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(testCase.want, testCase.exp(cfg)); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
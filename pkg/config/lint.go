@@ -0,0 +1,332 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/Masterminds/sprig/v3"
+	"github.com/hirosassa/tfcmt-gitlab/pkg/terraform"
+	yaml2 "gopkg.in/yaml.v2"
+	"gopkg.in/yaml.v3"
+)
+
+// Problem is a problem of the configuration file found by Lint.
+// Line and Column are zero if the position is unknown.
+type Problem struct {
+	Line    int
+	Column  int
+	Message string
+}
+
+func (p Problem) String() string {
+	if p.Line == 0 {
+		return p.Message
+	}
+	return fmt.Sprintf("%d:%d: %s", p.Line, p.Column, p.Message)
+}
+
+var colorPattern = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// Lint checks the configuration file strictly and returns all the problems found.
+// Unlike LoadFile, unknown keys are problems, and the templates, the label colors and
+// the complement entries are checked without running any command.
+func Lint(raw []byte) []Problem {
+	root := &yaml.Node{}
+	if err := yaml.Unmarshal(raw, root); err != nil {
+		return []Problem{{Message: err.Error()}}
+	}
+	l := &linter{
+		nodes: map[string]*yaml.Node{},
+	}
+	if len(root.Content) != 0 {
+		l.walk("", root.Content[0], reflect.TypeOf(Config{}))
+	}
+	if len(l.problems) != 0 {
+		return l.sort()
+	}
+
+	cfg := Config{}
+	if err := yaml2.Unmarshal(raw, &cfg); err != nil {
+		return []Problem{{Message: err.Error()}}
+	}
+	l.lintTemplates(&cfg)
+	l.lintLabels(&cfg)
+	if err := cfg.validateOptions(); err != nil {
+		l.problems = append(l.problems, Problem{Message: err.Error()})
+	}
+	return l.sort()
+}
+
+type linter struct {
+	problems []Problem
+	// nodes are the value nodes of the configuration keyed by the path such as terraform.plan.template
+	nodes map[string]*yaml.Node
+}
+
+func (l *linter) addf(node *yaml.Node, format string, a ...any) {
+	p := Problem{Message: fmt.Sprintf(format, a...)}
+	if node != nil {
+		p.Line = node.Line
+		p.Column = node.Column
+	}
+	l.problems = append(l.problems, p)
+}
+
+func (l *linter) sort() []Problem {
+	sort.SliceStable(l.problems, func(i, j int) bool {
+		if l.problems[i].Line != l.problems[j].Line {
+			return l.problems[i].Line < l.problems[j].Line
+		}
+		return l.problems[i].Column < l.problems[j].Column
+	})
+	return l.problems
+}
+
+// walk checks the node has the keys and the types of typ
+func (l *linter) walk(path string, node *yaml.Node, typ reflect.Type) { //nolint:cyclop
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!null" {
+		return
+	}
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	switch typ {
+	case reflect.TypeOf(Complement{}):
+		l.walkComplement(path, node)
+		return
+	case reflect.TypeOf(Extends{}):
+		if node.Kind == yaml.ScalarNode {
+			return
+		}
+	}
+
+	switch typ.Kind() { //nolint:exhaustive
+	case reflect.Struct:
+		if node.Kind != yaml.MappingNode {
+			l.addf(node, "%s must be a mapping", displayPath(path))
+			return
+		}
+		fields := yamlFields(typ)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, val := node.Content[i], node.Content[i+1]
+			field, ok := fields[key.Value]
+			if !ok {
+				if path == "" {
+					l.addf(key, "unknown field %q", key.Value)
+				} else {
+					l.addf(key, "unknown field %q in %s", key.Value, path)
+				}
+				continue
+			}
+			child := joinPath(path, key.Value)
+			l.nodes[child] = val
+			l.walk(child, val, field)
+		}
+	case reflect.Map:
+		if node.Kind != yaml.MappingNode {
+			l.addf(node, "%s must be a mapping", displayPath(path))
+			return
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			child := joinPath(path, node.Content[i].Value)
+			l.nodes[child] = node.Content[i+1]
+			l.walk(child, node.Content[i+1], typ.Elem())
+		}
+	case reflect.Slice:
+		if node.Kind != yaml.SequenceNode {
+			l.addf(node, "%s must be a list", displayPath(path))
+			return
+		}
+		for i, elem := range node.Content {
+			child := fmt.Sprintf("%s[%d]", path, i)
+			l.nodes[child] = elem
+			l.walk(child, elem, typ.Elem())
+		}
+	case reflect.Bool:
+		if node.Kind != yaml.ScalarNode || !isBool(node.Value) {
+			l.addf(node, "%s must be a boolean", displayPath(path))
+		}
+	case reflect.Int:
+		if _, err := strconv.Atoi(node.Value); node.Kind != yaml.ScalarNode || err != nil {
+			l.addf(node, "%s must be an integer", displayPath(path))
+		}
+	case reflect.String:
+		if node.Kind != yaml.ScalarNode {
+			l.addf(node, "%s must be a string", displayPath(path))
+		}
+	}
+}
+
+// walkComplement checks the complement entries such as ci.sha
+func (l *linter) walkComplement(path string, node *yaml.Node) {
+	entries := []string{"mr", "namespace", "project", "sha", "link"}
+	l.walk(path, node, reflect.TypeOf(rawComplement{}))
+	for _, name := range entries {
+		l.lintComplementEntries(joinPath(path, name))
+	}
+	vars, ok := l.nodes[joinPath(path, "vars")]
+	if !ok || vars.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(vars.Content); i += 2 {
+		l.lintComplementEntries(joinPath(joinPath(path, "vars"), vars.Content[i].Value))
+	}
+}
+
+func (l *linter) lintComplementEntries(path string) {
+	node, ok := l.nodes[path]
+	if !ok || node.Kind != yaml.SequenceNode {
+		return
+	}
+	for i, elem := range node.Content {
+		m := map[string]interface{}{}
+		if err := elem.Decode(&m); err != nil {
+			l.addf(elem, "%s[%d]: %v", path, i, err)
+			continue
+		}
+		entry, err := convComplementEntry(m)
+		if err != nil {
+			l.addf(elem, "%s[%d]: %v", path, i, err)
+			continue
+		}
+		if e, ok := entry.(*ComplementTemplateEntry); ok {
+			if _, err := template.New("_").Funcs(sprig.TxtFuncMap()).Parse(e.Value); err != nil {
+				l.addf(elem, "%s[%d]: parse a template: %v", path, i, err)
+			}
+		}
+	}
+}
+
+// lintTemplates parses all the templates in the configuration
+func (l *linter) lintTemplates(cfg *Config) {
+	raw := cfg.Terraform.UseRawOutput
+	templates := map[string]string{
+		"terraform.plan.template":                   cfg.Terraform.Plan.Template,
+		"terraform.plan.when_parse_error.template":  cfg.Terraform.Plan.WhenParseError.Template,
+		"terraform.plan_all.template":               cfg.Terraform.PlanAll.Template,
+		"terraform.apply.template":                  cfg.Terraform.Apply.Template,
+		"terraform.apply.when_parse_error.template": cfg.Terraform.Apply.WhenParseError.Template,
+		"terraform.drift.template":                  cfg.Terraform.Drift.Template,
+	}
+	for name, tpl := range cfg.Templates {
+		templates[joinPath("templates", name)] = tpl
+	}
+	for path, tpl := range templates {
+		if tpl == "" {
+			continue
+		}
+		t := &terraform.Template{Template: tpl}
+		t.UseRawOutput = raw
+		if err := t.Validate(); err != nil {
+			l.addf(l.nodes[path], "%s: %v", path, err)
+		}
+	}
+	for i, n := range cfg.Notifiers {
+		if n.Template == "" {
+			continue
+		}
+		// the webhooks always use the raw output
+		t := &terraform.Template{Template: n.Template}
+		t.UseRawOutput = true
+		if err := t.Validate(); err != nil {
+			path := fmt.Sprintf("notifiers[%d].template", i)
+			l.addf(l.nodes[path], "%s: %v", path, err)
+		}
+	}
+	// the names and the titles are rendered with the variables like the labels
+	names := map[string]string{
+		"terraform.drift.title":                          cfg.Terraform.Drift.Title,
+		"terraform.commit_status.name":                   cfg.Terraform.CommitStatus.Name,
+		"terraform.plan.when_destroy.approval_rule.name": cfg.Terraform.Plan.WhenDestroy.ApprovalRule.Name,
+	}
+	for path, tpl := range names {
+		if tpl == "" {
+			continue
+		}
+		if _, err := template.New("_").Funcs(sprig.TxtFuncMap()).Parse(tpl); err != nil {
+			l.addf(l.nodes[path], "%s: %v", path, err)
+		}
+	}
+}
+
+// lintLabels parses the label templates and checks the label colors
+func (l *linter) lintLabels(cfg *Config) {
+	plan := &cfg.Terraform.Plan
+	labels := map[string][2]string{
+		"terraform.plan.when_add_or_update_only": {plan.WhenAddOrUpdateOnly.Label, plan.WhenAddOrUpdateOnly.Color},
+		"terraform.plan.when_destroy":            {plan.WhenDestroy.Label, plan.WhenDestroy.Color},
+		"terraform.plan.when_no_changes":         {plan.WhenNoChanges.Label, plan.WhenNoChanges.Color},
+		"terraform.plan.when_plan_error":         {plan.WhenPlanError.Label, plan.WhenPlanError.Color},
+		"terraform.plan.when_moved":              {plan.WhenMoved.Label, plan.WhenMoved.Color},
+		"terraform.plan.when_imported":           {plan.WhenImported.Label, plan.WhenImported.Color},
+		"terraform.plan.when_forgotten":          {plan.WhenForgotten.Label, plan.WhenForgotten.Color},
+		"policy":                                 {cfg.Policy.Label, cfg.Policy.Color},
+	}
+	for path, label := range labels {
+		if label[0] != "" {
+			if _, err := template.New("_").Funcs(sprig.TxtFuncMap()).Parse(label[0]); err != nil {
+				l.addf(l.nodes[path+".label"], "%s.label: %v", path, err)
+			}
+		}
+		if label[1] != "" && !colorPattern.MatchString(label[1]) {
+			l.addf(l.nodes[path+".label_color"], "%s.label_color must be a hex color such as #d93f0b: %s", path, label[1])
+		}
+	}
+}
+
+// yamlFields returns the types of the fields keyed by the names in YAML like gopkg.in/yaml.v2
+func yamlFields(typ reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type, typ.NumField())
+	for i := range typ.NumField() {
+		f := typ.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, opts, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+		if name == "-" {
+			continue
+		}
+		if strings.Contains(opts, "inline") {
+			for k, v := range yamlFields(f.Type) {
+				fields[k] = v
+			}
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(f.Name)
+		}
+		fields[name] = f.Type
+	}
+	return fields
+}
+
+func isBool(s string) bool {
+	// the values which gopkg.in/yaml.v2 decodes as a boolean
+	switch strings.ToLower(s) {
+	case "true", "false", "yes", "no", "on", "off", "y", "n":
+		return true
+	}
+	return false
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func displayPath(path string) string {
+	if path == "" {
+		return "the configuration"
+	}
+	return path
+}
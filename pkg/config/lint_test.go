@@ -0,0 +1,154 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLint(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		name string
+		raw  string
+		exp  []Problem
+	}{
+		{
+			name: "valid",
+			raw: `
+terraform:
+  plan:
+    template: |
+      {{template "plan_title" .}} {{.Result}}
+    when_destroy:
+      label: "{{.Vars.target}}/destroy"
+      label_color: "#d93f0b"
+templates:
+  plan_title: "## Plan Result ({{.Vars.target}})"
+ci:
+  sha:
+    - type: envsubst
+      value: "$CI_COMMIT_SHA"
+  vars:
+    target:
+      - type: template
+        value: "{{ env \"TARGET\" }}"
+`,
+		},
+		{
+			name: "unknown fields",
+			raw: `
+terraform:
+  plan:
+    when_destory:
+      label: destroy
+    when_no_changes:
+      lable_color: "#0e8a16"
+`,
+			exp: []Problem{
+				{Line: 4, Column: 5, Message: `unknown field "when_destory" in terraform.plan`},
+				{Line: 7, Column: 7, Message: `unknown field "lable_color" in terraform.plan.when_no_changes`},
+			},
+		},
+		{
+			name: "invalid types",
+			raw: `
+plan_patch: sometimes
+terraform:
+  max_code_length: long
+`,
+			exp: []Problem{
+				{Line: 2, Column: 13, Message: "plan_patch must be a boolean"},
+				{Line: 4, Column: 20, Message: "terraform.max_code_length must be an integer"},
+			},
+		},
+		{
+			name: "invalid templates and colors",
+			raw: `
+terraform:
+  plan:
+    template: "{{if .HasDestroy}}"
+    when_destroy:
+      label: "{{.Vars.target"
+      label_color: red
+templates:
+  foo: "{{unknownFunc}}"
+`,
+			exp: []Problem{
+				{Line: 4, Column: 15, Message: `terraform.plan.template: template: default:1: unexpected EOF`},
+				{Line: 6, Column: 14, Message: `terraform.plan.when_destroy.label: template: _:1: unclosed action`},
+				{Line: 7, Column: 20, Message: `terraform.plan.when_destroy.label_color must be a hex color such as #d93f0b: red`},
+				{Line: 9, Column: 8, Message: `templates.foo: template: default:1: function "unknownFunc" not defined`},
+			},
+		},
+		{
+			name: "invalid drift, commit status and approval rule templates",
+			raw: `
+terraform:
+  drift:
+    template: "{{if .HasDestroy}}"
+    title: "Drift ({{.Vars.target}"
+  commit_status:
+    name: "tfcmt/{{.Command"
+  plan:
+    when_destroy:
+      approval_rule:
+        name: "{{unknownFunc}}"
+`,
+			exp: []Problem{
+				{Line: 4, Column: 15, Message: `terraform.drift.template: template: default:1: unexpected EOF`},
+				{Line: 5, Column: 12, Message: `terraform.drift.title: template: _:1: bad character U+007D '}'`},
+				{Line: 7, Column: 11, Message: `terraform.commit_status.name: template: _:1: unclosed action`},
+				{Line: 11, Column: 15, Message: `terraform.plan.when_destroy.approval_rule.name: template: _:1: function "unknownFunc" not defined`},
+			},
+		},
+		{
+			name: "invalid destroy state",
+			raw: `
+terraform:
+  commit_status:
+    destroy_state: blocked
+`,
+			exp: []Problem{
+				{Message: "commit_status.destroy_state must be pending, running, success, failed, canceled or skipped: blocked"},
+			},
+		},
+		{
+			name: "invalid complement entries",
+			raw: `
+ci:
+  sha:
+    - value: "$CI_COMMIT_SHA"
+  vars:
+    target:
+      - type: shell
+        value: echo foo
+`,
+			exp: []Problem{
+				{Line: 4, Column: 7, Message: `ci.sha[0]: "type" is required`},
+				{Line: 7, Column: 9, Message: `ci.vars.target[0]: unsupported type: shell`},
+			},
+		},
+		{
+			name: "invalid options",
+			raw: `
+terraform:
+  plan:
+    outdated_comment: archive
+`,
+			exp: []Problem{
+				{Message: "outdated_comment must be either hide or delete: archive"},
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+			problems := Lint([]byte(testCase.raw))
+			if diff := cmp.Diff(testCase.exp, problems); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// SchemaURL is the URL of the JSON Schema of the configuration file committed to the repository
+const SchemaURL = "https://raw.githubusercontent.com/hirosassa/tfcmt-gitlab/main/tfcmt.schema.json"
+
+// Schema returns the JSON Schema of the configuration file.
+// It's generated from the types of Config, so it's always in sync with LoadFile.
+// The values and the formats which can't be derived from the types are given by the jsonschema tag:
+// `jsonschema:"enum=a,b"` lists the allowed values and `jsonschema:"color"` means a hex color.
+func Schema() map[string]any {
+	schema := schemaOf(reflect.TypeOf(Config{}), "")
+	schema["$schema"] = "http://json-schema.org/draft-07/schema#"
+	schema["$id"] = SchemaURL
+	schema["title"] = "tfcmt-gitlab configuration"
+	schema["definitions"] = map[string]any{
+		"complementEntry": complementEntrySchema(),
+	}
+	return schema
+}
+
+// SchemaJSON returns the JSON Schema of the configuration file as indented JSON
+func SchemaJSON() ([]byte, error) {
+	b, err := json.MarshalIndent(Schema(), "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("marshal the JSON Schema: %w", err)
+	}
+	return append(b, '\n'), nil
+}
+
+func schemaOf(typ reflect.Type, tag string) map[string]any { //nolint:cyclop
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	switch typ {
+	case reflect.TypeOf(Complement{}):
+		return complementSchema()
+	case reflect.TypeOf(Extends{}):
+		return map[string]any{
+			"oneOf": []any{
+				map[string]any{"type": "string"},
+				map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+			},
+		}
+	}
+
+	var schema map[string]any
+	switch typ.Kind() { //nolint:exhaustive
+	case reflect.Struct:
+		properties := map[string]any{}
+		for i := range typ.NumField() {
+			f := typ.Field(i)
+			name, _, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+			if !f.IsExported() || name == "-" {
+				continue
+			}
+			if name == "" {
+				name = strings.ToLower(f.Name)
+			}
+			properties[name] = schemaOf(f.Type, f.Tag.Get("jsonschema"))
+		}
+		return map[string]any{
+			"type":                 "object",
+			"properties":           properties,
+			"additionalProperties": false,
+		}
+	case reflect.Map:
+		return map[string]any{
+			"type":                 "object",
+			"additionalProperties": schemaOf(typ.Elem(), ""),
+		}
+	case reflect.Slice:
+		return map[string]any{
+			"type":  "array",
+			"items": schemaOf(typ.Elem(), tag),
+		}
+	case reflect.Bool:
+		schema = map[string]any{"type": "boolean"}
+	case reflect.Int:
+		schema = map[string]any{"type": "integer"}
+	case reflect.String:
+		schema = map[string]any{"type": "string"}
+	default:
+		schema = map[string]any{}
+	}
+
+	switch {
+	case tag == "color":
+		schema["pattern"] = colorPattern.String()
+	case strings.HasPrefix(tag, "enum="):
+		schema["enum"] = strings.Split(strings.TrimPrefix(tag, "enum="), ",")
+	}
+	return schema
+}
+
+// complementSchema returns the schema of the ci key whose entries are a union of the entry types
+func complementSchema() map[string]any {
+	entries := map[string]any{
+		"type":  "array",
+		"items": map[string]any{"$ref": "#/definitions/complementEntry"},
+	}
+	properties := map[string]any{
+		"vars": map[string]any{
+			"type":                 "object",
+			"additionalProperties": entries,
+		},
+	}
+	for _, name := range []string{"mr", "namespace", "project", "sha", "link"} {
+		properties[name] = entries
+	}
+	return map[string]any{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+}
+
+func complementEntrySchema() map[string]any {
+	types := []string{
+		(&ComplementEnvsubstEntry{}).Type(),
+		(&ComplementTemplateEntry{}).Type(),
+	}
+	oneOf := make([]any, len(types))
+	for i, typ := range types {
+		oneOf[i] = map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"type":  map[string]any{"const": typ},
+				"value": map[string]any{"type": "string"},
+			},
+			"required":             []string{"type", "value"},
+			"additionalProperties": false,
+		}
+	}
+	return map[string]any{"oneOf": oneOf}
+}
@@ -0,0 +1,40 @@
+package config
+
+import (
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSchemaJSON(t *testing.T) {
+	t.Parallel()
+	b, err := SchemaJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp, err := os.ReadFile("../../tfcmt.schema.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(string(exp), string(b)); diff != "" {
+		t.Errorf("tfcmt.schema.json is outdated. Please run make schema:\n%s", diff)
+	}
+}
+
+func TestSchema(t *testing.T) {
+	t.Parallel()
+	schema := Schema()
+	terraform := schema["properties"].(map[string]any)["terraform"].(map[string]any)["properties"].(map[string]any) //nolint:forcetypeassert
+	plan := terraform["plan"].(map[string]any)["properties"].(map[string]any)                                       //nolint:forcetypeassert
+	if diff := cmp.Diff(map[string]any{"type": "string", "enum": []string{"hide", "delete"}}, plan["outdated_comment"]); diff != "" {
+		t.Error(diff)
+	}
+	whenDestroy := plan["when_destroy"].(map[string]any)["properties"].(map[string]any) //nolint:forcetypeassert
+	if diff := cmp.Diff(map[string]any{"type": "string", "pattern": colorPattern.String()}, whenDestroy["label_color"]); diff != "" {
+		t.Error(diff)
+	}
+	if _, ok := schema["properties"].(map[string]any)["ci"].(map[string]any)["properties"].(map[string]any)["sha"]; !ok { //nolint:forcetypeassert
+		t.Error("ci.sha isn't in the schema")
+	}
+}
@@ -8,6 +8,8 @@ import (
 	"io"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"slices"
 	"text/template"
 
 	"github.com/Masterminds/sprig/v3"
@@ -15,9 +17,11 @@ import (
 	"github.com/hirosassa/tfcmt-gitlab/pkg/config"
 	"github.com/hirosassa/tfcmt-gitlab/pkg/notifier"
 	"github.com/hirosassa/tfcmt-gitlab/pkg/notifier/gitlab"
+	"github.com/hirosassa/tfcmt-gitlab/pkg/notifier/webhook"
 	"github.com/hirosassa/tfcmt-gitlab/pkg/platform"
 	"github.com/hirosassa/tfcmt-gitlab/pkg/terraform"
 	"github.com/mattn/go-colorable"
+	"github.com/sirupsen/logrus"
 )
 
 type Controller struct {
@@ -25,11 +29,21 @@ type Controller struct {
 	Parser             terraform.Parser
 	Template           *terraform.Template
 	ParseErrorTemplate *terraform.Template
+	// PlanJSONFile is a path to the JSON representation of the plan
+	PlanJSONFile string
+	// PlanFile is a path to the saved plan file. It's converted to JSON by `terraform show -json`
+	PlanFile string
+	// InputFile is a path to the output of the command executed in advance. "-" means the standard input
+	InputFile string
+	// ExitCode is the exit code of the command executed in advance. It's used with InputFile
+	ExitCode int
 }
 
 type Command struct {
 	Cmd  string
 	Args []string
+	// Dir is the working directory of the command. If it's empty, the command is run in the current directory
+	Dir string
 }
 
 // Run sends the notification with notifier
@@ -38,10 +52,14 @@ func (ctrl *Controller) Run(ctx context.Context, command Command) error {
 		return err
 	}
 
-	if err := ctrl.Config.Validate(); err != nil {
+	if err := ctrl.validate(); err != nil {
 		return err
 	}
 
+	if ctrl.Config.Terraform.Tool == "" {
+		ctrl.Config.Terraform.Tool = terraform.DetectTool(command.Cmd)
+	}
+
 	ntf, err := ctrl.getNotifier(ctx)
 	if err != nil {
 		return err
@@ -51,25 +69,116 @@ func (ctrl *Controller) Run(ctx context.Context, command Command) error {
 		return errors.New("no notifier specified at all")
 	}
 
+	var param notifier.ParamExec
+	if ctrl.InputFile != "" {
+		if command.Cmd != "" {
+			return errors.New("a command can't be given with an input file")
+		}
+		p, err := ctrl.readInput()
+		if err != nil {
+			return err
+		}
+		param = p
+	} else {
+		if command.Cmd == "" {
+			return errors.New("a command or an input file is required")
+		}
+		param = ctrl.execute(ctx, command, os.Stdout, os.Stderr)
+	}
+
+	planJSON, err := ctrl.readPlanJSON(ctx, "")
+	if err != nil {
+		logrus.WithError(err).Warn("read the plan as JSON. The output of the command is parsed instead")
+	}
+	param.PlanJSON = planJSON
+	param.CIName = ctrl.Config.CI.Name
+
+	return apperr.NewExitError(ntf.Notify(param))
+}
+
+// validate validates the configuration.
+// The drift is reported as an issue, so neither a merge request nor a commit is required
+func (ctrl *Controller) validate() error {
+	if ctrl.commandName() == "drift" {
+		return ctrl.Config.ValidateDrift()
+	}
+	return ctrl.Config.Validate()
+}
+
+// execute runs the command and captures its output. The output is also written to stdout and stderr
+func (ctrl *Controller) execute(ctx context.Context, command Command, stdoutW, stderrW io.Writer) notifier.ParamExec {
 	cmd := exec.CommandContext(ctx, command.Cmd, command.Args...) //nolint:gosec
+	cmd.Dir = command.Dir
 	stdout := &bytes.Buffer{}
 	stderr := &bytes.Buffer{}
 	combinedOutput := &bytes.Buffer{}
 	uncolorizedStdout := colorable.NewNonColorable(stdout)
 	uncolorizedStderr := colorable.NewNonColorable(stderr)
 	uncolorizedCombinedOutput := colorable.NewNonColorable(combinedOutput)
-	cmd.Stdout = io.MultiWriter(os.Stdout, uncolorizedStdout, uncolorizedCombinedOutput)
-	cmd.Stderr = io.MultiWriter(os.Stderr, uncolorizedStderr, uncolorizedCombinedOutput)
+	cmd.Stdout = io.MultiWriter(stdoutW, uncolorizedStdout, uncolorizedCombinedOutput)
+	cmd.Stderr = io.MultiWriter(stderrW, uncolorizedStderr, uncolorizedCombinedOutput)
 	_ = cmd.Run()
 
-	return apperr.NewExitError(ntf.Notify(notifier.ParamExec{
+	return notifier.ParamExec{
 		Stdout:         stdout.String(),
 		Stderr:         stderr.String(),
 		CombinedOutput: combinedOutput.String(),
 		Cmd:            cmd,
-		CIName:         ctrl.Config.CI.Name,
 		ExitCode:       cmd.ProcessState.ExitCode(),
-	}))
+	}
+}
+
+// readInput reads the output of the command which was executed in advance from InputFile.
+// If InputFile is "-", the output is read from the standard input.
+func (ctrl *Controller) readInput() (notifier.ParamExec, error) {
+	var r io.Reader = os.Stdin
+	if ctrl.InputFile != "-" {
+		f, err := os.Open(ctrl.InputFile)
+		if err != nil {
+			return notifier.ParamExec{}, fmt.Errorf("open an input file %s: %w", ctrl.InputFile, err)
+		}
+		defer f.Close()
+		r = f
+	}
+
+	output := &bytes.Buffer{}
+	if _, err := io.Copy(colorable.NewNonColorable(output), r); err != nil {
+		return notifier.ParamExec{}, fmt.Errorf("read an input file %s: %w", ctrl.InputFile, err)
+	}
+
+	return notifier.ParamExec{
+		Stdout:         output.String(),
+		CombinedOutput: output.String(),
+		ExitCode:       ctrl.ExitCode,
+	}, nil
+}
+
+// readPlanJSON returns the JSON representation of the plan if either PlanJSONFile or PlanFile is given.
+// Relative paths are resolved from dir.
+func (ctrl *Controller) readPlanJSON(ctx context.Context, dir string) (string, error) {
+	if ctrl.PlanJSONFile != "" {
+		p := ctrl.PlanJSONFile
+		if !filepath.IsAbs(p) {
+			p = filepath.Join(dir, p)
+		}
+		b, err := os.ReadFile(p)
+		if err != nil {
+			return "", fmt.Errorf("read a plan JSON file %s: %w", p, err)
+		}
+		return string(b), nil
+	}
+	if ctrl.PlanFile != "" {
+		stdout := &bytes.Buffer{}
+		cmd := exec.CommandContext(ctx, terraform.ToolCommand(ctrl.Config.Terraform.Tool), "show", "-json", ctrl.PlanFile) //nolint:gosec
+		cmd.Dir = dir
+		cmd.Stdout = stdout
+		cmd.Stderr = os.Stderr
+		if err := cmd.Run(); err != nil {
+			return "", fmt.Errorf("convert a plan file %s to JSON: %w", ctrl.PlanFile, err)
+		}
+		return stdout.String(), nil
+	}
+	return "", nil
 }
 
 func (ctrl *Controller) renderTemplate(tpl string) (string, error) {
@@ -88,10 +197,14 @@ func (ctrl *Controller) renderTemplate(tpl string) (string, error) {
 
 func (ctrl *Controller) renderGitHubLabels() (gitlab.ResultLabels, error) { //nolint:cyclop
 	labels := gitlab.ResultLabels{
-		AddOrUpdateLabelColor: ctrl.Config.Terraform.Plan.WhenAddOrUpdateOnly.Color,
-		DestroyLabelColor:     ctrl.Config.Terraform.Plan.WhenDestroy.Color,
-		NoChangesLabelColor:   ctrl.Config.Terraform.Plan.WhenNoChanges.Color,
-		PlanErrorLabelColor:   ctrl.Config.Terraform.Plan.WhenPlanError.Color,
+		AddOrUpdateLabelColor:     ctrl.Config.Terraform.Plan.WhenAddOrUpdateOnly.Color,
+		DestroyLabelColor:         ctrl.Config.Terraform.Plan.WhenDestroy.Color,
+		NoChangesLabelColor:       ctrl.Config.Terraform.Plan.WhenNoChanges.Color,
+		PlanErrorLabelColor:       ctrl.Config.Terraform.Plan.WhenPlanError.Color,
+		MovedLabelColor:           ctrl.Config.Terraform.Plan.WhenMoved.Color,
+		ImportedLabelColor:        ctrl.Config.Terraform.Plan.WhenImported.Color,
+		ForgottenLabelColor:       ctrl.Config.Terraform.Plan.WhenForgotten.Color,
+		PolicyViolationLabelColor: ctrl.Config.Policy.Color,
 	}
 
 	target, ok := ctrl.Config.Vars["target"]
@@ -108,6 +221,15 @@ func (ctrl *Controller) renderGitHubLabels() (gitlab.ResultLabels, error) { //no
 	if labels.NoChangesLabelColor == "" {
 		labels.NoChangesLabelColor = "#0e8a16" // green
 	}
+	if labels.MovedLabelColor == "" {
+		labels.MovedLabelColor = "#fbca04" // yellow
+	}
+	if labels.ImportedLabelColor == "" {
+		labels.ImportedLabelColor = "#5319e7" // purple
+	}
+	if labels.ForgottenLabelColor == "" {
+		labels.ForgottenLabelColor = "#c5def5" // light blue
+	}
 
 	if ctrl.Config.Terraform.Plan.WhenAddOrUpdateOnly.Label == "" {
 		if target == "" {
@@ -157,10 +279,168 @@ func (ctrl *Controller) renderGitHubLabels() (gitlab.ResultLabels, error) { //no
 	}
 	labels.PlanErrorLabel = planErrorLabel
 
+	if ctrl.Config.Terraform.Plan.WhenMoved.Label == "" {
+		if target == "" {
+			labels.MovedLabel = "moved"
+		} else {
+			labels.MovedLabel = target + "/moved"
+		}
+	} else {
+		movedLabel, err := ctrl.renderTemplate(ctrl.Config.Terraform.Plan.WhenMoved.Label)
+		if err != nil {
+			return labels, err
+		}
+		labels.MovedLabel = movedLabel
+	}
+
+	if ctrl.Config.Terraform.Plan.WhenImported.Label == "" {
+		if target == "" {
+			labels.ImportedLabel = "imported"
+		} else {
+			labels.ImportedLabel = target + "/imported"
+		}
+	} else {
+		importedLabel, err := ctrl.renderTemplate(ctrl.Config.Terraform.Plan.WhenImported.Label)
+		if err != nil {
+			return labels, err
+		}
+		labels.ImportedLabel = importedLabel
+	}
+
+	if ctrl.Config.Terraform.Plan.WhenForgotten.Label == "" {
+		if target == "" {
+			labels.ForgottenLabel = "forgotten"
+		} else {
+			labels.ForgottenLabel = target + "/forgotten"
+		}
+	} else {
+		forgottenLabel, err := ctrl.renderTemplate(ctrl.Config.Terraform.Plan.WhenForgotten.Label)
+		if err != nil {
+			return labels, err
+		}
+		labels.ForgottenLabel = forgottenLabel
+	}
+
+	if len(ctrl.Config.Policy.Rules) != 0 {
+		if labels.PolicyViolationLabelColor == "" {
+			labels.PolicyViolationLabelColor = "#b60205" // dark red
+		}
+		if ctrl.Config.Policy.Label == "" {
+			if target == "" {
+				labels.PolicyViolationLabel = "policy-violation"
+			} else {
+				labels.PolicyViolationLabel = target + "/policy-violation"
+			}
+		} else {
+			policyViolationLabel, err := ctrl.renderTemplate(ctrl.Config.Policy.Label)
+			if err != nil {
+				return labels, err
+			}
+			labels.PolicyViolationLabel = policyViolationLabel
+		}
+	}
+
 	return labels, nil
 }
 
 func (ctrl *Controller) getNotifier(ctx context.Context) (notifier.Notifier, error) {
+	client, err := ctrl.newGitLabClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var ntf notifier.Notifier = client.Notify
+	if ctrl.commandName() == "drift" {
+		ntf = client.Drift
+	}
+	webhooks, err := ctrl.newWebhookClients(ctx, ctrl.commandName())
+	if err != nil {
+		return nil, err
+	}
+	if len(webhooks) == 0 {
+		return ntf, nil
+	}
+	ntfs := notifier.Notifiers{ntf}
+	for _, w := range webhooks {
+		ntfs = append(ntfs, w)
+	}
+	return ntfs, nil
+}
+
+func (ctrl *Controller) getMultiNotifier(ctx context.Context) (notifier.MultiNotifier, error) {
+	client, err := ctrl.newGitLabClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	webhooks, err := ctrl.newWebhookClients(ctx, "plan-all")
+	if err != nil {
+		return nil, err
+	}
+	if len(webhooks) == 0 {
+		return client.Notify, nil
+	}
+	ntfs := notifier.MultiNotifiers{client.Notify}
+	for _, w := range webhooks {
+		ntfs = append(ntfs, w)
+	}
+	return ntfs, nil
+}
+
+// commandName returns the name of the command which is notified
+func (ctrl *Controller) commandName() string {
+	switch ctrl.Parser.(type) {
+	case *terraform.ApplyParser:
+		return "apply"
+	case *terraform.DriftParser:
+		return "drift"
+	default:
+		return "plan"
+	}
+}
+
+// newWebhookClients returns the webhook clients which are configured for the command
+func (ctrl *Controller) newWebhookClients(ctx context.Context, command string) ([]*webhook.Client, error) {
+	if ctrl.Config.DryRun {
+		if len(ctrl.Config.Notifiers) != 0 {
+			logrus.WithFields(logrus.Fields{
+				"program": "tfcmt",
+			}).Info("skip the webhooks in dry run")
+		}
+		return nil, nil
+	}
+	clients := make([]*webhook.Client, 0, len(ctrl.Config.Notifiers))
+	for _, n := range ctrl.Config.Notifiers {
+		if len(n.Commands) != 0 && !slices.Contains(n.Commands, command) {
+			continue
+		}
+		var tpl *terraform.Template
+		if n.Template != "" {
+			tpl = &terraform.Template{Template: n.Template}
+		}
+		client, err := webhook.NewClient(webhook.Config{
+			Type:             n.Type,
+			URL:              n.URL,
+			Headers:          n.Headers,
+			Command:          command,
+			Parser:           ctrl.Parser,
+			DetailedExitCode: ctrl.Config.Terraform.Plan.DetailedExitCode,
+			ChangesExitCode:  ctrl.Config.Terraform.Plan.ChangesExitCode,
+			Policy:           ctrl.policyRules(),
+			Tool:             ctrl.Config.Terraform.Tool,
+			Template:         tpl,
+			OnlyFailure:      n.OnlyFailure,
+			CI:               ctrl.Config.CI.Link,
+			Vars:             ctrl.Config.Vars,
+			Templates:        ctrl.Config.Templates,
+		})
+		if err != nil {
+			return nil, err
+		}
+		clients = append(clients, client.WithContext(ctx))
+	}
+	return clients, nil
+}
+
+func (ctrl *Controller) newGitLabClient(ctx context.Context) (*gitlab.Client, error) {
 	labels := gitlab.ResultLabels{}
 	if !ctrl.Config.Terraform.Plan.DisableLabel {
 		a, err := ctrl.renderGitHubLabels()
@@ -169,6 +449,15 @@ func (ctrl *Controller) getNotifier(ctx context.Context) (notifier.Notifier, err
 		}
 		labels = a
 	}
+	approvalRule, err := ctrl.destroyApprovalRule()
+	if err != nil {
+		return nil, err
+	}
+	driftIssue, err := ctrl.driftIssue()
+	if err != nil {
+		return nil, err
+	}
+	ctrl.setMaxCodeLength()
 	client, err := gitlab.NewClient(gitlab.Config{
 		Token:     ctrl.Config.GitLabToken,
 		BaseURL:   ctrl.Config.BaseURL,
@@ -178,20 +467,134 @@ func (ctrl *Controller) getNotifier(ctx context.Context) (notifier.Notifier, err
 			Revision: ctrl.Config.CI.SHA,
 			Number:   ctrl.Config.CI.MRNumber,
 		},
-		CI:                 ctrl.Config.CI.Link,
-		Parser:             ctrl.Parser,
-		UseRawOutput:       ctrl.Config.Terraform.UseRawOutput,
-		Template:           ctrl.Template,
-		ParseErrorTemplate: ctrl.ParseErrorTemplate,
-		ResultLabels:       labels,
-		Vars:               ctrl.Config.Vars,
-		EmbeddedVarNames:   ctrl.Config.EmbeddedVarNames,
-		Templates:          ctrl.Config.Templates,
-		Patch:              ctrl.Config.PlanPatch,
-		SkipNoChanges:      ctrl.Config.Terraform.Plan.WhenNoChanges.DisableComment,
+		CI:                    ctrl.Config.CI.Link,
+		Parser:                ctrl.Parser,
+		Tool:                  ctrl.Config.Terraform.Tool,
+		UseRawOutput:          ctrl.Config.Terraform.UseRawOutput,
+		Template:              ctrl.Template,
+		ParseErrorTemplate:    ctrl.ParseErrorTemplate,
+		ResultLabels:          labels,
+		Vars:                  ctrl.Config.Vars,
+		EmbeddedVarNames:      ctrl.Config.EmbeddedVarNames,
+		Templates:             ctrl.Config.Templates,
+		Patch:                 ctrl.Config.PlanPatch,
+		SkipNoChanges:         ctrl.Config.Terraform.Plan.WhenNoChanges.DisableComment,
+		DetailedExitCode:      ctrl.Config.Terraform.Plan.DetailedExitCode,
+		ChangesExitCode:       ctrl.Config.Terraform.Plan.ChangesExitCode,
+		Discussion:            ctrl.Config.Terraform.Plan.Discussion.Enabled,
+		AutoResolveDiscussion: ctrl.Config.Terraform.Plan.Discussion.AutoResolve,
+		LinkPlan:              ctrl.Config.Terraform.Apply.LinkPlan,
+		OutdatedComment:       ctrl.Config.Terraform.Plan.OutdatedComment,
+		SplitComment:          ctrl.Config.Terraform.SplitComment,
+		FullOutput:            ctrl.Config.Terraform.FullOutput.Type,
+		FullOutputPath:        ctrl.Config.Terraform.FullOutput.Path,
+		TerraformReportPath:   ctrl.Config.Terraform.Plan.TerraformReportPath,
+		JobName:               ctrl.Config.CI.JobName,
+		PipelineID:            ctrl.Config.CI.PipelineID,
+		PipelineSHA:           ctrl.Config.CI.PipelineSHA,
+		CommitStatus:          ctrl.commitStatus(),
+		DestroyApprovalRule:   approvalRule,
+		Drift:                 driftIssue,
+		Policy:                ctrl.policyRules(),
+		DryRun:                ctrl.Config.DryRun,
 	})
 	if err != nil {
 		return nil, err
 	}
-	return client.Notify, nil
+	return client, nil
+}
+
+// commitStatus returns the configuration of the commit status. If it's disabled, the name is empty
+func (ctrl *Controller) commitStatus() gitlab.CommitStatus {
+	cfg := ctrl.Config.Terraform.CommitStatus
+	if !cfg.Enabled {
+		return gitlab.CommitStatus{}
+	}
+	status := gitlab.CommitStatus{
+		Name:         cfg.Name,
+		DestroyState: cfg.DestroyState,
+	}
+	if status.Name == "" {
+		status.Name = gitlab.DefaultCommitStatusName
+	}
+	if status.DestroyState == "" {
+		status.DestroyState = gitlab.DefaultCommitStatusDestroyState
+	}
+	return status
+}
+
+// destroyApprovalRule returns the approval rule which is required while the plan destroys resources.
+// If it isn't configured, the rule without a name is returned.
+func (ctrl *Controller) destroyApprovalRule() (gitlab.ApprovalRule, error) {
+	cfg := ctrl.Config.Terraform.Plan.WhenDestroy.ApprovalRule
+	if !cfg.Enabled() {
+		return gitlab.ApprovalRule{}, nil
+	}
+	rule := gitlab.ApprovalRule{
+		Name:              "tfcmt destroy",
+		ApprovalsRequired: cfg.ApprovalsRequired,
+		UserIDs:           cfg.UserIDs,
+		GroupIDs:          cfg.GroupIDs,
+	}
+	if target := ctrl.Config.Vars["target"]; target != "" {
+		rule.Name += " (" + target + ")"
+	}
+	if cfg.Name != "" {
+		name, err := ctrl.renderTemplate(cfg.Name)
+		if err != nil {
+			return rule, err
+		}
+		rule.Name = name
+	}
+	if rule.ApprovalsRequired == 0 {
+		rule.ApprovalsRequired = 1
+	}
+	return rule, nil
+}
+
+// driftIssue returns the configuration of the issue which is opened while the drift is detected
+func (ctrl *Controller) driftIssue() (gitlab.DriftIssue, error) {
+	cfg := ctrl.Config.Terraform.Drift
+	title := cfg.Title
+	if title == "" {
+		title = gitlab.DefaultDriftIssueTitle
+	}
+	title, err := ctrl.renderTemplate(title)
+	if err != nil {
+		return gitlab.DriftIssue{}, err
+	}
+	return gitlab.DriftIssue{
+		Title:       title,
+		Labels:      cfg.Labels,
+		AssigneeIDs: cfg.AssigneeIDs,
+	}, nil
+}
+
+// policyRules returns the policy rules which are evaluated against the plan result
+func (ctrl *Controller) policyRules() []terraform.PolicyRule {
+	rules := make([]terraform.PolicyRule, len(ctrl.Config.Policy.Rules))
+	for i, rule := range ctrl.Config.Policy.Rules {
+		rules[i] = terraform.PolicyRule{
+			Name:       rule.Name,
+			Actions:    rule.Actions,
+			Resources:  rule.Resources,
+			MaxChanges: rule.MaxChanges,
+		}
+	}
+	return rules
+}
+
+// setMaxCodeLength sets the maximum length of the code block to the templates.
+// When the comment is split into multiple notes, the code block isn't truncated.
+// Discussions aren't split, so the code block is truncated in the discussion mode.
+func (ctrl *Controller) setMaxCodeLength() {
+	maxCodeLength := ctrl.Config.Terraform.MaxCodeLength
+	if ctrl.Config.Terraform.SplitComment && !ctrl.Config.Terraform.Plan.Discussion.Enabled {
+		maxCodeLength = terraform.NoCodeLengthLimit
+	}
+	for _, tpl := range []*terraform.Template{ctrl.Template, ctrl.ParseErrorTemplate} {
+		if tpl != nil {
+			tpl.MaxCodeLength = maxCodeLength
+		}
+	}
 }
@@ -0,0 +1,72 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/hirosassa/tfcmt-gitlab/pkg/platform"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	goldmarkhtml "github.com/yuin/goldmark/renderer/html"
+)
+
+// Render renders the comment from the output in InputFile without posting it to GitLab
+func (ctrl *Controller) Render(ctx context.Context) (string, error) {
+	if err := platform.Complement(&ctrl.Config); err != nil {
+		return "", err
+	}
+
+	if ctrl.InputFile == "" {
+		return "", errors.New("an input file is required")
+	}
+
+	// nothing is posted, so neither a GitLab token nor a merge request is needed
+	ctrl.Config.DryRun = true
+	client, err := ctrl.newGitLabClient(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	param, err := ctrl.readInput()
+	if err != nil {
+		return "", err
+	}
+
+	planJSON, err := ctrl.readPlanJSON(ctx, "")
+	if err != nil {
+		return "", err
+	}
+	param.PlanJSON = planJSON
+	param.CIName = ctrl.Config.CI.Name
+
+	return client.Notify.Render(param)
+}
+
+// RenderHTML converts the rendered comment to a standalone HTML page.
+// Raw HTML in the comment such as <details> is kept as GitLab does.
+func RenderHTML(markdown string) (string, error) {
+	md := goldmark.New(
+		goldmark.WithExtensions(extension.GFM),
+		goldmark.WithRendererOptions(goldmarkhtml.WithUnsafe()),
+	)
+	buf := &bytes.Buffer{}
+	buf.WriteString(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>tfcmt-gitlab preview</title>
+<style>
+body { max-width: 980px; margin: 2em auto; font-family: sans-serif; }
+pre { background: #f6f8fa; padding: 1em; overflow: auto; }
+</style>
+</head>
+<body>
+`)
+	if err := md.Convert([]byte(markdown), buf); err != nil {
+		return "", fmt.Errorf("convert the comment to HTML: %w", err)
+	}
+	buf.WriteString("</body>\n</html>\n")
+	return buf.String(), nil
+}
@@ -0,0 +1,120 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/hirosassa/tfcmt-gitlab/pkg/apperr"
+	"github.com/hirosassa/tfcmt-gitlab/pkg/notifier"
+	"github.com/hirosassa/tfcmt-gitlab/pkg/platform"
+	"github.com/hirosassa/tfcmt-gitlab/pkg/terraform"
+	"github.com/sirupsen/logrus"
+)
+
+// defaultConcurrency is the number of the commands which are run at the same time by RunAll
+const defaultConcurrency = 4
+
+// RunAll runs the command in each directory with bounded concurrency and sends one notification for all of them
+func (ctrl *Controller) RunAll(ctx context.Context, command Command, patterns []string, concurrency int) error {
+	if err := platform.Complement(&ctrl.Config); err != nil {
+		return err
+	}
+
+	if err := ctrl.Config.Validate(); err != nil {
+		return err
+	}
+
+	if command.Cmd == "" {
+		return errors.New("a command is required")
+	}
+
+	dirs, err := expandDirs(patterns)
+	if err != nil {
+		return err
+	}
+	if len(dirs) == 0 {
+		return errors.New("no directory is found")
+	}
+
+	if ctrl.Config.Terraform.Tool == "" {
+		ctrl.Config.Terraform.Tool = terraform.DetectTool(command.Cmd)
+	}
+
+	ntf, err := ctrl.getMultiNotifier(ctx)
+	if err != nil {
+		return err
+	}
+
+	if ntf == nil {
+		return errors.New("no notifier specified at all")
+	}
+
+	if concurrency <= 0 {
+		concurrency = defaultConcurrency
+	}
+
+	params := make([]notifier.ParamExec, len(dirs))
+	semaphore := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+	for i, dir := range dirs {
+		wg.Add(1)
+		go func(i int, dir string) {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			// buffer the output not to interleave the output of the commands run at the same time
+			output := &bytes.Buffer{}
+			cmd := command
+			cmd.Dir = dir
+			param := ctrl.execute(ctx, cmd, output, output)
+
+			planJSON, err := ctrl.readPlanJSON(ctx, dir)
+			if err != nil {
+				logrus.WithError(err).WithField("dir", dir).Warn("read the plan as JSON. The output of the command is parsed instead")
+			}
+			param.PlanJSON = planJSON
+			param.CIName = ctrl.Config.CI.Name
+			param.Target = dir
+			params[i] = param
+
+			mutex.Lock()
+			defer mutex.Unlock()
+			fmt.Fprintf(os.Stdout, "==> %s (exit code: %d)\n%s\n", dir, param.ExitCode, output.String())
+		}(i, dir)
+	}
+	wg.Wait()
+
+	return apperr.NewExitError(ntf.NotifyAll(params))
+}
+
+// expandDirs expands glob patterns into a sorted list of unique directories
+func expandDirs(patterns []string) ([]string, error) {
+	found := map[string]struct{}{}
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("expand a glob pattern %s: %w", pattern, err)
+		}
+		for _, match := range matches {
+			fi, err := os.Stat(match)
+			if err != nil || !fi.IsDir() {
+				continue
+			}
+			found[filepath.Clean(match)] = struct{}{}
+		}
+	}
+	dirs := make([]string, 0, len(found))
+	for dir := range found {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+	return dirs, nil
+}
@@ -0,0 +1,116 @@
+package gitlab
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/hirosassa/tfcmt-gitlab/pkg/terraform"
+	"github.com/sirupsen/logrus"
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+// ApprovalRule is the merge request approval rule which is required while the plan destroys resources
+type ApprovalRule struct {
+	// Name identifies the rule on the merge request. If it's empty, no approval rule is managed
+	Name              string
+	ApprovalsRequired int
+	UserIDs           []int
+	GroupIDs          []int
+}
+
+// updateDestroyApprovalRule creates or updates the approval rule when the plan destroys resources,
+// and deletes it when the plan succeeds without destroying anything.
+// If the plan fails, the rule is kept because it's unknown whether the changes destroy resources.
+func (g *NotifyService) updateDestroyApprovalRule(result terraform.ParseResult) error {
+	cfg := g.client.Config
+	rule := cfg.DestroyApprovalRule
+	if !result.HasDestroy && (result.HasPlanError || result.HasParseError) {
+		return nil
+	}
+
+	rules, _, err := g.client.API.ListMergeRequestApprovalRules(cfg.MR.Number)
+	if err != nil {
+		return fmt.Errorf("list the approval rules: %w", err)
+	}
+	var current *gitlab.MergeRequestApprovalRule
+	for _, r := range rules {
+		if r.Name == rule.Name {
+			current = r
+			break
+		}
+	}
+
+	logE := logrus.WithFields(logrus.Fields{
+		"program":       "tfcmt",
+		"approval_rule": rule.Name,
+	})
+
+	if !result.HasDestroy {
+		if current == nil {
+			return nil
+		}
+		logE.Debug("delete the approval rule because the plan doesn't destroy resources")
+		if _, err := g.client.API.DeleteMergeRequestApprovalRule(cfg.MR.Number, int(current.ID)); err != nil {
+			return fmt.Errorf("delete the approval rule %s: %w", rule.Name, err)
+		}
+		return nil
+	}
+
+	if current == nil {
+		logE.Debug("create the approval rule because the plan destroys resources")
+		if _, _, err := g.client.API.CreateMergeRequestApprovalRule(cfg.MR.Number, &gitlab.CreateMergeRequestApprovalRuleOptions{
+			Name:              gitlab.Ptr(rule.Name),
+			ApprovalsRequired: gitlab.Ptr(int64(rule.ApprovalsRequired)),
+			UserIDs:           gitlab.Ptr(toInt64s(rule.UserIDs)),
+			GroupIDs:          gitlab.Ptr(toInt64s(rule.GroupIDs)),
+		}); err != nil {
+			return fmt.Errorf("create the approval rule %s: %w", rule.Name, err)
+		}
+		return nil
+	}
+
+	if isSameApprovalRule(current, rule) {
+		return nil
+	}
+	logE.Debug("update the approval rule")
+	if _, _, err := g.client.API.UpdateMergeRequestApprovalRule(cfg.MR.Number, int(current.ID), &gitlab.UpdateMergeRequestApprovalRuleOptions{
+		ApprovalsRequired: gitlab.Ptr(int64(rule.ApprovalsRequired)),
+		UserIDs:           gitlab.Ptr(toInt64s(rule.UserIDs)),
+		GroupIDs:          gitlab.Ptr(toInt64s(rule.GroupIDs)),
+	}); err != nil {
+		return fmt.Errorf("update the approval rule %s: %w", rule.Name, err)
+	}
+	return nil
+}
+
+// isSameApprovalRule returns true if the existing rule already requires the approvals of the configured rule
+func isSameApprovalRule(current *gitlab.MergeRequestApprovalRule, rule ApprovalRule) bool {
+	if current.ApprovalsRequired != int64(rule.ApprovalsRequired) {
+		return false
+	}
+	userIDs := make([]int64, len(current.Users))
+	for i, user := range current.Users {
+		userIDs[i] = user.ID
+	}
+	groupIDs := make([]int64, len(current.Groups))
+	for i, group := range current.Groups {
+		groupIDs[i] = group.ID
+	}
+	return sameIDs(userIDs, toInt64s(rule.UserIDs)) && sameIDs(groupIDs, toInt64s(rule.GroupIDs))
+}
+
+func sameIDs(a, b []int64) bool {
+	a = slices.Clone(a)
+	b = slices.Clone(b)
+	slices.Sort(a)
+	slices.Sort(b)
+	return slices.Equal(slices.Compact(a), slices.Compact(b))
+}
+
+func toInt64s(ids []int) []int64 {
+	ret := make([]int64, len(ids))
+	for i, id := range ids {
+		ret[i] = int64(id)
+	}
+	return ret
+}
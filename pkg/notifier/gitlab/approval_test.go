@@ -0,0 +1,105 @@
+package gitlab
+
+import (
+	"testing"
+
+	gitlabmock "github.com/hirosassa/tfcmt-gitlab/pkg/notifier/gitlab/gen"
+	"github.com/hirosassa/tfcmt-gitlab/pkg/terraform"
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+	"go.uber.org/mock/gomock"
+)
+
+func TestNotifyUpdateDestroyApprovalRule(t *testing.T) {
+	t.Parallel()
+	existing := &gitlab.MergeRequestApprovalRule{
+		ID:                10,
+		Name:              "tfcmt destroy",
+		ApprovalsRequired: 1,
+		Groups:            []*gitlab.Group{{ID: 5}},
+	}
+	testCases := []struct {
+		name                string
+		createMockGitLabAPI func(ctrl *gomock.Controller) *gitlabmock.MockAPI
+		result              terraform.ParseResult
+	}{
+		{
+			name: "create the rule on destroy",
+			createMockGitLabAPI: func(ctrl *gomock.Controller) *gitlabmock.MockAPI {
+				api := gitlabmock.NewMockAPI(ctrl)
+				api.EXPECT().ListMergeRequestApprovalRules(1).Return([]*gitlab.MergeRequestApprovalRule{{ID: 3, Name: "other"}}, nil, nil)
+				api.EXPECT().CreateMergeRequestApprovalRule(1, &gitlab.CreateMergeRequestApprovalRuleOptions{
+					Name:              gitlab.Ptr("tfcmt destroy"),
+					ApprovalsRequired: gitlab.Ptr(int64(1)),
+					UserIDs:           gitlab.Ptr([]int64{}),
+					GroupIDs:          gitlab.Ptr([]int64{5}),
+				}).Return(&gitlab.MergeRequestApprovalRule{ID: 10}, nil, nil)
+				return api
+			},
+			result: terraform.ParseResult{HasDestroy: true},
+		},
+		{
+			name: "keep the same rule on destroy",
+			createMockGitLabAPI: func(ctrl *gomock.Controller) *gitlabmock.MockAPI {
+				api := gitlabmock.NewMockAPI(ctrl)
+				api.EXPECT().ListMergeRequestApprovalRules(1).Return([]*gitlab.MergeRequestApprovalRule{existing}, nil, nil)
+				return api
+			},
+			result: terraform.ParseResult{HasDestroy: true},
+		},
+		{
+			name: "update the changed rule on destroy",
+			createMockGitLabAPI: func(ctrl *gomock.Controller) *gitlabmock.MockAPI {
+				api := gitlabmock.NewMockAPI(ctrl)
+				api.EXPECT().ListMergeRequestApprovalRules(1).Return([]*gitlab.MergeRequestApprovalRule{
+					{ID: 10, Name: "tfcmt destroy", ApprovalsRequired: 2},
+				}, nil, nil)
+				api.EXPECT().UpdateMergeRequestApprovalRule(1, 10, &gitlab.UpdateMergeRequestApprovalRuleOptions{
+					ApprovalsRequired: gitlab.Ptr(int64(1)),
+					UserIDs:           gitlab.Ptr([]int64{}),
+					GroupIDs:          gitlab.Ptr([]int64{5}),
+				}).Return(existing, nil, nil)
+				return api
+			},
+			result: terraform.ParseResult{HasDestroy: true},
+		},
+		{
+			name: "delete the rule when nothing is destroyed",
+			createMockGitLabAPI: func(ctrl *gomock.Controller) *gitlabmock.MockAPI {
+				api := gitlabmock.NewMockAPI(ctrl)
+				api.EXPECT().ListMergeRequestApprovalRules(1).Return([]*gitlab.MergeRequestApprovalRule{existing}, nil, nil)
+				api.EXPECT().DeleteMergeRequestApprovalRule(1, 10).Return(nil, nil)
+				return api
+			},
+			result: terraform.ParseResult{HasAddOrUpdateOnly: true},
+		},
+		{
+			name: "keep the rule when the plan fails",
+			createMockGitLabAPI: func(ctrl *gomock.Controller) *gitlabmock.MockAPI {
+				return gitlabmock.NewMockAPI(ctrl)
+			},
+			result: terraform.ParseResult{HasPlanError: true},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+			mockCtrl := gomock.NewController(t)
+			cfg := newFakeConfig()
+			cfg.DestroyApprovalRule = ApprovalRule{
+				Name:              "tfcmt destroy",
+				ApprovalsRequired: 1,
+				GroupIDs:          []int{5},
+			}
+			client, err := NewClient(cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			client.API = testCase.createMockGitLabAPI(mockCtrl)
+			if err := client.Notify.updateDestroyApprovalRule(testCase.result); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
@@ -2,10 +2,12 @@ package gitlab
 
 import (
 	"errors"
+	"io"
 	"os"
 	"strings"
 
 	"github.com/hirosassa/tfcmt-gitlab/pkg/terraform"
+	"github.com/sirupsen/logrus"
 	gitlab "gitlab.com/gitlab-org/api/client-go"
 )
 
@@ -15,6 +17,16 @@ const EnvToken = "GITLAB_TOKEN"
 // EnvBaseURL is GitLab base URL. This can be set to a domain endpoint to use with Private GitLab.
 const EnvBaseURL = "GITLAB_BASE_URL"
 
+const (
+	// OutdatedCommentHide collapses the outdated comments
+	OutdatedCommentHide = "hide"
+	// OutdatedCommentDelete deletes the outdated comments
+	OutdatedCommentDelete = "delete"
+)
+
+// dryRunMergeRequest is the placeholder merge request number used in dry run
+const dryRunMergeRequest = 1
+
 // Client ...
 type Client struct {
 	*gitlab.Client
@@ -23,9 +35,11 @@ type Client struct {
 
 	common service
 
-	Comment *CommentService
-	Commits *CommitsService
-	Notify  *NotifyService
+	Comment    *CommentService
+	Commits    *CommitsService
+	Discussion *DiscussionService
+	Drift      *DriftService
+	Notify     *NotifyService
 
 	API API
 }
@@ -38,7 +52,15 @@ type Config struct {
 	Project   string
 	MR        MergeRequest
 	CI        string
-	Parser    terraform.Parser
+	// PipelineID is the ID of the CI pipeline. It's embedded in the comment as metadata
+	PipelineID string
+	// PipelineSHA is the commit SHA which the CI pipeline runs for.
+	// The commit status is associated with the pipeline only if the commit status is set to this commit
+	PipelineSHA string
+	Parser      terraform.Parser
+	// Tool is the tool which runs the command such as terraform.ToolOpenTofu.
+	// If it's empty, the tool is detected from the output
+	Tool string
 	// Template is used for all Terraform command output
 	Template           *terraform.Template
 	ParseErrorTemplate *terraform.Template
@@ -50,6 +72,45 @@ type Config struct {
 	UseRawOutput     bool
 	Patch            bool
 	SkipNoChanges    bool
+	// DetailedExitCode means the exit code of terraform plan follows `-detailed-exitcode`
+	DetailedExitCode bool
+	// ChangesExitCode is the exit code which tfcmt returns when the plan has changes with DetailedExitCode
+	ChangesExitCode int
+	// Discussion means the plan result is posted as a resolvable discussion instead of a note
+	Discussion bool
+	// AutoResolveDiscussion means the discussion is resolved when the plan has no destroy and no error
+	AutoResolveDiscussion bool
+	// LinkPlan means the apply result links to the last plan comment of the same target and is compared with it.
+	// In the discussion mode, the apply result is replied to the thread of the plan
+	LinkPlan bool
+	// OutdatedComment is the strategy for the older comments of the same target when a new comment is posted.
+	// OutdatedCommentHide or OutdatedCommentDelete. If it's empty, the older comments are kept as they are.
+	OutdatedComment string
+	// SplitComment means the body which exceeds MaxCommentLength is split into multiple notes
+	SplitComment bool
+	// MaxCommentLength is the maximum length of a note. If it's zero, the maximum length of GitLab note is used
+	MaxCommentLength int
+	// FullOutput is the way to upload the full output of the command. FullOutputSnippet or FullOutputArtifact.
+	// If it's empty, the full output isn't uploaded
+	FullOutput string
+	// FullOutputPath is the path of the file written with FullOutputArtifact.
+	// It has to be relative to the project directory to be linked
+	FullOutputPath string
+	// TerraformReportPath is the path of the report for the Terraform widget of merge requests.
+	// If it's empty, the report isn't written
+	TerraformReportPath string
+	// JobName is the name of the CI job. It's written in the report for the Terraform widget
+	JobName string
+	// CommitStatus is the commit status which is set per target
+	CommitStatus CommitStatus
+	// DestroyApprovalRule is the approval rule which is required while the plan destroys resources
+	DestroyApprovalRule ApprovalRule
+	// Drift is the issue which is opened while the drift is detected
+	Drift DriftIssue
+	// Policy is the rules of the changes which aren't allowed. The violations fail the command
+	Policy []terraform.PolicyRule
+	// DryRun means nothing is written to GitLab. The comments and the label operations are printed to stdout instead
+	DryRun bool
 }
 
 // MergeRequest represents GitLab Merge Request metadata
@@ -66,6 +127,10 @@ type service struct {
 
 // NewClient returns Client initialized with Config
 func NewClient(cfg Config) (*Client, error) {
+	if cfg.DryRun {
+		return newDryRunClient(cfg, os.Stdout), nil
+	}
+
 	token := getToken(cfg)
 	if token == "" {
 		return &Client{}, errors.New("gitlab token is missing")
@@ -83,15 +148,8 @@ func NewClient(cfg Config) (*Client, error) {
 		}
 	}
 
-	c := &Client{
-		Config: cfg,
-		Client: client,
-	}
-	c.common.client = c
-	c.Comment = (*CommentService)(&c.common)
-	c.Commits = (*CommitsService)(&c.common)
-	c.Notify = (*NotifyService)(&c.common)
-
+	c := newClient(cfg)
+	c.Client = client
 	c.API = &GitLab{
 		Client:    client,
 		namespace: cfg.NameSpace,
@@ -101,6 +159,35 @@ func NewClient(cfg Config) (*Client, error) {
 	return c, nil
 }
 
+// newDryRunClient returns Client which prints the operations to w instead of calling GitLab API.
+// If neither the merge request nor the revision is given, a placeholder merge request is used
+// so that the label operations are printed too.
+func newDryRunClient(cfg Config, w io.Writer) *Client {
+	if !cfg.MR.IsNumber() && cfg.MR.Revision == "" {
+		logrus.WithFields(logrus.Fields{
+			"program":       "tfcmt",
+			"merge_request": dryRunMergeRequest,
+		}).Debug("use a placeholder merge request in dry run because neither merge request nor revision is given")
+		cfg.MR.Number = dryRunMergeRequest
+	}
+	c := newClient(cfg)
+	c.API = &DryRunAPI{Writer: w}
+	return c
+}
+
+func newClient(cfg Config) *Client {
+	c := &Client{
+		Config: cfg,
+	}
+	c.common.client = c
+	c.Comment = (*CommentService)(&c.common)
+	c.Commits = (*CommitsService)(&c.common)
+	c.Discussion = (*DiscussionService)(&c.common)
+	c.Drift = (*DriftService)(&c.common)
+	c.Notify = (*NotifyService)(&c.common)
+	return c
+}
+
 // IsNumber returns true if MergeRequest is Merge Request build
 func (mr *MergeRequest) IsNumber() bool {
 	return mr.Number != 0
@@ -108,19 +195,28 @@ func (mr *MergeRequest) IsNumber() bool {
 
 // ResultLabels represents the labels to add to the PR depending on the plan result
 type ResultLabels struct {
-	AddOrUpdateLabel      string
-	DestroyLabel          string
-	NoChangesLabel        string
-	PlanErrorLabel        string
-	AddOrUpdateLabelColor string
-	DestroyLabelColor     string
-	NoChangesLabelColor   string
-	PlanErrorLabelColor   string
+	AddOrUpdateLabel          string
+	DestroyLabel              string
+	NoChangesLabel            string
+	PlanErrorLabel            string
+	MovedLabel                string
+	ImportedLabel             string
+	ForgottenLabel            string
+	PolicyViolationLabel      string
+	AddOrUpdateLabelColor     string
+	DestroyLabelColor         string
+	NoChangesLabelColor       string
+	PlanErrorLabelColor       string
+	MovedLabelColor           string
+	ImportedLabelColor        string
+	ForgottenLabelColor       string
+	PolicyViolationLabelColor string
 }
 
 // HasAnyLabelDefined returns true if any of the internal labels are set
 func (r *ResultLabels) HasAnyLabelDefined() bool {
-	return r.AddOrUpdateLabel != "" || r.DestroyLabel != "" || r.NoChangesLabel != "" || r.PlanErrorLabel != ""
+	return r.AddOrUpdateLabel != "" || r.DestroyLabel != "" || r.NoChangesLabel != "" || r.PlanErrorLabel != "" ||
+		r.MovedLabel != "" || r.ImportedLabel != "" || r.ForgottenLabel != "" || r.PolicyViolationLabel != ""
 }
 
 // IsResultLabel returns true if a label matches any of the internal labels
@@ -128,7 +224,7 @@ func (r *ResultLabels) IsResultLabel(label string) bool {
 	switch label {
 	case "":
 		return false
-	case r.AddOrUpdateLabel, r.DestroyLabel, r.NoChangesLabel, r.PlanErrorLabel:
+	case r.AddOrUpdateLabel, r.DestroyLabel, r.NoChangesLabel, r.PlanErrorLabel, r.MovedLabel, r.ImportedLabel, r.ForgottenLabel, r.PolicyViolationLabel:
 		return true
 	default:
 		return false
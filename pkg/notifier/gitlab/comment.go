@@ -2,6 +2,7 @@ package gitlab
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/sirupsen/logrus"
 	gitlab "gitlab.com/gitlab-org/api/client-go"
@@ -12,6 +13,9 @@ const (
 	maxPages    = 100
 )
 
+// outdatedCommentHeader is the first line of the comment which is hidden because it is outdated
+const outdatedCommentHeader = ":information_source: This result is outdated. See the newer result below."
+
 // CommentService handles communication with the comment related
 // methods of GitLab API
 type CommentService service
@@ -45,6 +49,15 @@ func (g *CommentService) Post(body string, opt PostOptions) error {
 	return err
 }
 
+// PostNote posts comment to the merge request and returns the created note
+func (g *CommentService) PostNote(body string, number int) (*gitlab.Note, error) {
+	note, _, err := g.client.API.CreateMergeRequestNote(
+		number,
+		&gitlab.CreateMergeRequestNoteOptions{Body: gitlab.Ptr(body)},
+	)
+	return note, err
+}
+
 func (g *CommentService) postForRevision(body, revision string) error {
 	_, _, err := g.client.API.PostCommitComment(
 		revision,
@@ -63,6 +76,29 @@ func (g *CommentService) Patch(note int, body string, opt PostOptions) error {
 	return err
 }
 
+// Delete deletes the specific comment
+func (g *CommentService) Delete(note int, opt PostOptions) error {
+	_, err := g.client.API.DeleteMergeRequestNote(opt.Number, note)
+	return err
+}
+
+// Hide collapses the specific comment into a short stub because it is outdated.
+// The original content is kept in the <details> tag.
+func (g *CommentService) Hide(note *gitlab.Note, opt PostOptions) error {
+	if isHiddenComment(note.Body) {
+		return nil
+	}
+	return g.Patch(int(note.ID), hiddenCommentBody(note.Body), opt)
+}
+
+func hiddenCommentBody(body string) string {
+	return outdatedCommentHeader + "\n\n<details><summary>Outdated result (Click me)</summary>\n\n" + body + "\n</details>\n"
+}
+
+func isHiddenComment(body string) bool {
+	return strings.HasPrefix(body, outdatedCommentHeader)
+}
+
 // List lists comments on GitLab merge requests
 func (g *CommentService) List(number int) ([]*gitlab.Note, error) {
 	allComments := make([]*gitlab.Note, 0)
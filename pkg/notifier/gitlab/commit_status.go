@@ -0,0 +1,120 @@
+package gitlab
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/Masterminds/sprig/v3"
+	"github.com/hirosassa/tfcmt-gitlab/pkg/terraform"
+	"github.com/sirupsen/logrus"
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+// DefaultCommitStatusName is the default template of the name of the commit status such as tfcmt/plan/prod
+const DefaultCommitStatusName = "tfcmt/{{.Command}}{{if .Target}}/{{.Target}}{{end}}"
+
+// DefaultCommitStatusDestroyState is the default state of the commit status when the plan destroys resources.
+// Unlike pending and canceled, skipped doesn't block the pipeline from succeeding
+const DefaultCommitStatusDestroyState = "skipped"
+
+// maxCommitStatusDescription is the maximum length of the description of the commit status
+const maxCommitStatusDescription = 255
+
+// CommitStatus is a configuration of the commit status which is set per target
+type CommitStatus struct {
+	// Name is the template of the name of the commit status. It's rendered with .Command, .Target and .Vars.
+	// If it's empty, no commit status is set
+	Name string
+	// DestroyState is the state of the commit status when the plan destroys resources
+	DestroyState string
+}
+
+// setCommitStatus sets the commit status of the target derived from the result
+func (g *NotifyService) setCommitStatus(command, target string, result terraform.ParseResult) error {
+	cfg := g.client.Config
+	name, err := g.commitStatusName(command, target)
+	if err != nil {
+		return err
+	}
+	// the commit SHA is complemented from the CI environment variables
+	sha := cfg.MR.Revision
+	if sha == "" {
+		return errors.New("the commit SHA is unknown")
+	}
+
+	state, description := commitStatusState(command, result, cfg.CommitStatus.DestroyState)
+	opt := &gitlab.SetCommitStatusOptions{
+		State:       state,
+		Name:        gitlab.Ptr(name),
+		Description: gitlab.Ptr(description),
+	}
+	if cfg.CI != "" {
+		opt.TargetURL = gitlab.Ptr(cfg.CI)
+	}
+	// The pipeline is specified only if it runs for the commit.
+	// In merged results pipelines, the pipeline runs for the merge commit instead of the head of the source branch
+	if sha == cfg.PipelineSHA {
+		if id, err := strconv.ParseInt(cfg.PipelineID, 10, 64); err == nil {
+			opt.PipelineID = gitlab.Ptr(id)
+		}
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"program": "tfcmt",
+		"name":    name,
+		"state":   state,
+		"sha":     sha,
+	}).Debug("set the commit status")
+	if _, _, err := g.client.API.SetCommitStatus(sha, opt); err != nil {
+		return fmt.Errorf("set the commit status %s: %w", name, err)
+	}
+	return nil
+}
+
+func (g *NotifyService) commitStatusName(command, target string) (string, error) {
+	cfg := g.client.Config
+	tmpl, err := template.New("_").Funcs(sprig.TxtFuncMap()).Parse(cfg.CommitStatus.Name)
+	if err != nil {
+		return "", fmt.Errorf("parse the name of the commit status: %w", err)
+	}
+	buf := &bytes.Buffer{}
+	if err := tmpl.Execute(buf, map[string]interface{}{
+		"Command": command,
+		"Target":  target,
+		"Vars":    cfg.Vars,
+	}); err != nil {
+		return "", fmt.Errorf("render the name of the commit status: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// commitStatusState returns the state and the description of the commit status.
+// The failure of the plan is derived from the result instead of the exit code
+// because the exit code of the plan with changes can be ExitFail with the detailed exit code
+func commitStatusState(command string, result terraform.ParseResult, destroyState string) (gitlab.BuildStateValue, string) {
+	switch {
+	case result.HasParseError:
+		return gitlab.Failed, "the output can't be parsed"
+	case result.HasPlanError, command == commandApply && result.ExitCode != terraform.ExitPass:
+		return gitlab.Failed, truncateDescription(result.Result)
+	case len(result.PolicyViolations) != 0:
+		return gitlab.Failed, fmt.Sprintf("%d policy violations", len(result.PolicyViolations))
+	case result.HasDestroy:
+		return gitlab.BuildStateValue(destroyState), truncateDescription(result.Result)
+	default:
+		return gitlab.Success, truncateDescription(result.Result)
+	}
+}
+
+// truncateDescription returns the first line of the result within the maximum length of the description
+func truncateDescription(result string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(result), "\n")
+	if len(line) > maxCommitStatusDescription {
+		return line[:maxCommitStatusDescription-3] + "..."
+	}
+	return line
+}
@@ -0,0 +1,158 @@
+package gitlab
+
+import (
+	"testing"
+
+	gitlabmock "github.com/hirosassa/tfcmt-gitlab/pkg/notifier/gitlab/gen"
+	"github.com/hirosassa/tfcmt-gitlab/pkg/terraform"
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+	"go.uber.org/mock/gomock"
+)
+
+func TestNotifySetCommitStatus(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		name                string
+		createMockGitLabAPI func(ctrl *gomock.Controller) *gitlabmock.MockAPI
+		revision            string
+		pipelineSHA         string // the revision if it's empty
+		command             string
+		target              string
+		result              terraform.ParseResult
+	}{
+		{
+			name: "the pipeline isn't specified if it runs for the merge commit",
+			createMockGitLabAPI: func(ctrl *gomock.Controller) *gitlabmock.MockAPI {
+				api := gitlabmock.NewMockAPI(ctrl)
+				api.EXPECT().SetCommitStatus("abcd", &gitlab.SetCommitStatusOptions{
+					State:       gitlab.Success,
+					Name:        gitlab.Ptr("tfcmt/plan/prod"),
+					Description: gitlab.Ptr("Plan: 1 to add, 0 to change, 0 to destroy."),
+					TargetURL:   gitlab.Ptr("https://gitlab.com/owner/repo/-/jobs/1"),
+				}).Return(nil, nil, nil)
+				return api
+			},
+			revision:    "abcd",
+			pipelineSHA: "merge",
+			command:     commandPlan,
+			target:      "prod",
+			result: terraform.ParseResult{
+				Result:             "Plan: 1 to add, 0 to change, 0 to destroy.",
+				HasAddOrUpdateOnly: true,
+			},
+		},
+		{
+			name: "plan without destroy",
+			createMockGitLabAPI: func(ctrl *gomock.Controller) *gitlabmock.MockAPI {
+				api := gitlabmock.NewMockAPI(ctrl)
+				api.EXPECT().SetCommitStatus("abcd", &gitlab.SetCommitStatusOptions{
+					State:       gitlab.Success,
+					Name:        gitlab.Ptr("tfcmt/plan/prod"),
+					Description: gitlab.Ptr("Plan: 1 to add, 0 to change, 0 to destroy."),
+					TargetURL:   gitlab.Ptr("https://gitlab.com/owner/repo/-/jobs/1"),
+					PipelineID:  gitlab.Ptr(int64(100)),
+				}).Return(nil, nil, nil)
+				return api
+			},
+			revision: "abcd",
+			command:  commandPlan,
+			target:   "prod",
+			result: terraform.ParseResult{
+				Result:             "Plan: 1 to add, 0 to change, 0 to destroy.",
+				HasAddOrUpdateOnly: true,
+			},
+		},
+		{
+			name: "plan with changes and the detailed exit code",
+			createMockGitLabAPI: func(ctrl *gomock.Controller) *gitlabmock.MockAPI {
+				api := gitlabmock.NewMockAPI(ctrl)
+				api.EXPECT().SetCommitStatus("abcd", &gitlab.SetCommitStatusOptions{
+					State:       gitlab.Success,
+					Name:        gitlab.Ptr("tfcmt/plan"),
+					Description: gitlab.Ptr("Plan: 1 to add, 0 to change, 0 to destroy."),
+					TargetURL:   gitlab.Ptr("https://gitlab.com/owner/repo/-/jobs/1"),
+					PipelineID:  gitlab.Ptr(int64(100)),
+				}).Return(nil, nil, nil)
+				return api
+			},
+			revision: "abcd",
+			command:  commandPlan,
+			result: terraform.ParseResult{
+				Result:             "Plan: 1 to add, 0 to change, 0 to destroy.",
+				HasAddOrUpdateOnly: true,
+				HasChanges:         true,
+				// ChangesExitCode is 1
+				ExitCode: terraform.ExitFail,
+			},
+		},
+		{
+			name: "plan with destroy",
+			createMockGitLabAPI: func(ctrl *gomock.Controller) *gitlabmock.MockAPI {
+				api := gitlabmock.NewMockAPI(ctrl)
+				api.EXPECT().SetCommitStatus("abcd", &gitlab.SetCommitStatusOptions{
+					State:       gitlab.Skipped,
+					Name:        gitlab.Ptr("tfcmt/plan"),
+					Description: gitlab.Ptr("Plan: 0 to add, 0 to change, 1 to destroy."),
+					TargetURL:   gitlab.Ptr("https://gitlab.com/owner/repo/-/jobs/1"),
+					PipelineID:  gitlab.Ptr(int64(100)),
+				}).Return(nil, nil, nil)
+				return api
+			},
+			revision: "abcd",
+			command:  commandPlan,
+			result: terraform.ParseResult{
+				Result:     "Plan: 0 to add, 0 to change, 1 to destroy.",
+				HasDestroy: true,
+			},
+		},
+		{
+			name: "failed apply",
+			createMockGitLabAPI: func(ctrl *gomock.Controller) *gitlabmock.MockAPI {
+				api := gitlabmock.NewMockAPI(ctrl)
+				api.EXPECT().SetCommitStatus("efgh", &gitlab.SetCommitStatusOptions{
+					State:       gitlab.Failed,
+					Name:        gitlab.Ptr("tfcmt/apply/prod"),
+					Description: gitlab.Ptr("Error: Batch \"project/tfcmt-test\" already exists"),
+					TargetURL:   gitlab.Ptr("https://gitlab.com/owner/repo/-/jobs/1"),
+					PipelineID:  gitlab.Ptr(int64(100)),
+				}).Return(nil, nil, nil)
+				return api
+			},
+			revision: "efgh",
+			command:  commandApply,
+			target:   "prod",
+			result: terraform.ParseResult{
+				Result:   "Error: Batch \"project/tfcmt-test\" already exists\n\n  on main.tf line 1",
+				ExitCode: terraform.ExitFail,
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+			mockCtrl := gomock.NewController(t)
+			cfg := newFakeConfig()
+			cfg.MR.Revision = testCase.revision
+			cfg.CI = "https://gitlab.com/owner/repo/-/jobs/1"
+			cfg.PipelineID = "100"
+			cfg.PipelineSHA = testCase.pipelineSHA
+			if cfg.PipelineSHA == "" {
+				cfg.PipelineSHA = testCase.revision
+			}
+			cfg.CommitStatus = CommitStatus{
+				Name:         DefaultCommitStatusName,
+				DestroyState: DefaultCommitStatusDestroyState,
+			}
+			client, err := NewClient(cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			client.API = testCase.createMockGitLabAPI(mockCtrl)
+			if err := client.Notify.setCommitStatus(testCase.command, testCase.target, testCase.result); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
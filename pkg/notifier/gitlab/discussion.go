@@ -0,0 +1,102 @@
+package gitlab
+
+import (
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+// DiscussionService handles communication with the discussion related
+// methods of GitLab API
+type DiscussionService service
+
+// Post creates a resolvable discussion on the merge request
+func (g *DiscussionService) Post(body string, number int) (*gitlab.Discussion, error) {
+	if number == 0 {
+		return nil, fmt.Errorf("gitlab.discussion.post: Number is required")
+	}
+
+	discussion, _, err := g.client.API.CreateMergeRequestDiscussion(
+		number,
+		&gitlab.CreateMergeRequestDiscussionOptions{Body: gitlab.Ptr(body)},
+	)
+	return discussion, err
+}
+
+// Reply adds a note to the discussion
+func (g *DiscussionService) Reply(discussion, body string, number int) error {
+	_, _, err := g.client.API.AddMergeRequestDiscussionNote(
+		number,
+		discussion,
+		&gitlab.AddMergeRequestDiscussionNoteOptions{Body: gitlab.Ptr(body)},
+	)
+	return err
+}
+
+// Patch patches the specific note of the discussion
+func (g *DiscussionService) Patch(discussion string, note int, body string, number int) error {
+	_, _, err := g.client.API.UpdateMergeRequestDiscussionNote(
+		number,
+		discussion,
+		note,
+		&gitlab.UpdateMergeRequestDiscussionNoteOptions{Body: gitlab.Ptr(body)},
+	)
+	return err
+}
+
+// Resolve resolves or unresolves the discussion
+func (g *DiscussionService) Resolve(discussion string, resolved bool, number int) error {
+	_, _, err := g.client.API.ResolveMergeRequestDiscussion(
+		number,
+		discussion,
+		&gitlab.ResolveMergeRequestDiscussionOptions{Resolved: gitlab.Ptr(resolved)},
+	)
+	return err
+}
+
+// List lists discussions on GitLab merge requests.
+// Individual notes, which aren't threads, are excluded.
+func (g *DiscussionService) List(number int) ([]*gitlab.Discussion, error) {
+	allDiscussions := make([]*gitlab.Discussion, 0)
+
+	opt := &gitlab.ListMergeRequestDiscussionsOptions{
+		ListOptions: gitlab.ListOptions{
+			Page:    1,
+			PerPage: listPerPage,
+		},
+	}
+
+	for sentinel := 1; ; sentinel++ {
+		discussions, resp, err := g.client.API.ListMergeRequestDiscussions(
+			number,
+			opt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, discussion := range discussions {
+			if discussion.IndividualNote || len(discussion.Notes) == 0 {
+				continue
+			}
+			allDiscussions = append(allDiscussions, discussion)
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+
+		if sentinel >= maxPages {
+			logE := logrus.WithFields(logrus.Fields{
+				"program": "tfcmt",
+			})
+			logE.WithField("maxPages", maxPages).Debug("gitlab.discussion.list: too many pages, something went wrong")
+			break
+		}
+
+		opt.Page = resp.NextPage
+	}
+
+	return allDiscussions, nil
+}
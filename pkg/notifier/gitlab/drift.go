@@ -0,0 +1,144 @@
+package gitlab
+
+import (
+	"fmt"
+
+	"github.com/hirosassa/tfcmt-gitlab/pkg/notifier"
+	"github.com/hirosassa/tfcmt-gitlab/pkg/terraform"
+	"github.com/sirupsen/logrus"
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+// DefaultDriftIssueTitle is the default template of the title of the drift issue
+const DefaultDriftIssueTitle = "Drift detected{{if .Vars.target}} ({{.Vars.target}}){{end}}"
+
+// DriftIssue is a configuration of the issue which is opened while the drift is detected
+type DriftIssue struct {
+	Title string
+	// Labels are added to the issue. The open issues are filtered by them to find the issue of the target
+	Labels      []string
+	AssigneeIDs []int
+}
+
+// DriftService handles the issues of the drift detection
+type DriftService service
+
+// Notify opens or updates the issue of the target when the drift is detected, and closes it when the drift disappears.
+// If the plan fails, the issue is kept as it is because it's unknown whether the drift remains.
+// The exit code is ExitPass even if the drift is detected so that the scheduled pipeline doesn't fail.
+func (g *DriftService) Notify(param notifier.ParamExec) (int, error) {
+	cfg := g.client.Config
+	result := g.client.Notify.parse(param)
+	if result.HasParseError {
+		return terraform.ExitFail, result.Error
+	}
+
+	logE := logrus.WithFields(logrus.Fields{
+		"program": "tfcmt",
+		"target":  cfg.Vars["target"],
+	})
+
+	if result.HasPlanError {
+		logE.Warn("keep the drift issue because the plan failed")
+		return terraform.ExitFail, nil
+	}
+
+	meta := g.client.Notify.newMetadata(commandDrift)
+	issue, err := g.findIssue(meta)
+	if err != nil {
+		return terraform.ExitFail, err
+	}
+
+	if !result.HasDrift {
+		if issue == nil {
+			logE.Debug("no drift is detected")
+			return terraform.ExitPass, nil
+		}
+		logE.WithField("issue", issue.IID).Info("close the drift issue because the drift has disappeared")
+		if _, _, err := g.client.API.UpdateIssue(int(issue.IID), &gitlab.UpdateIssueOptions{
+			StateEvent: gitlab.Ptr("close"),
+		}); err != nil {
+			return terraform.ExitFail, fmt.Errorf("close the drift issue #%d: %w", issue.IID, err)
+		}
+		return terraform.ExitPass, nil
+	}
+
+	template := cfg.Template
+	template.SetValue(g.client.Notify.commonTemplate(param, result, nil, ""))
+	body, err := template.Execute()
+	if err != nil {
+		return terraform.ExitFail, err
+	}
+	body, err = meta.Embed(body)
+	if err != nil {
+		return terraform.ExitFail, err
+	}
+
+	var labels *gitlab.LabelOptions
+	if len(cfg.Drift.Labels) != 0 {
+		labels = gitlab.Ptr(gitlab.LabelOptions(cfg.Drift.Labels))
+	}
+	if issue == nil {
+		opt := &gitlab.CreateIssueOptions{
+			Title:       gitlab.Ptr(cfg.Drift.Title),
+			Description: gitlab.Ptr(body),
+			Labels:      labels,
+		}
+		if len(cfg.Drift.AssigneeIDs) != 0 {
+			opt.AssigneeIDs = gitlab.Ptr(toInt64s(cfg.Drift.AssigneeIDs))
+		}
+		logE.Info("open a drift issue")
+		if _, _, err := g.client.API.CreateIssue(opt); err != nil {
+			return terraform.ExitFail, fmt.Errorf("create a drift issue: %w", err)
+		}
+		return terraform.ExitPass, nil
+	}
+
+	// the assignees aren't changed not to overwrite the assignees changed by hand
+	logE.WithField("issue", issue.IID).Info("update the drift issue")
+	if _, _, err := g.client.API.UpdateIssue(int(issue.IID), &gitlab.UpdateIssueOptions{
+		Title:       gitlab.Ptr(cfg.Drift.Title),
+		Description: gitlab.Ptr(body),
+		AddLabels:   labels,
+	}); err != nil {
+		return terraform.ExitFail, fmt.Errorf("update the drift issue #%d: %w", issue.IID, err)
+	}
+	return terraform.ExitPass, nil
+}
+
+// findIssue returns the open issue of the same target. It returns nil if it isn't found
+func (g *DriftService) findIssue(meta *Metadata) (*gitlab.Issue, error) {
+	cfg := g.client.Config
+	opt := &gitlab.ListProjectIssuesOptions{
+		ListOptions: gitlab.ListOptions{
+			Page:    1,
+			PerPage: listPerPage,
+		},
+		State: gitlab.Ptr("opened"),
+	}
+	if len(cfg.Drift.Labels) != 0 {
+		opt.Labels = gitlab.Ptr(gitlab.LabelOptions(cfg.Drift.Labels))
+	}
+
+	for sentinel := 1; ; sentinel++ {
+		issues, resp, err := g.client.API.ListProjectIssues(opt)
+		if err != nil {
+			return nil, fmt.Errorf("list the issues: %w", err)
+		}
+		for _, issue := range issues {
+			if m := extractMetadata(issue.Description); m != nil && meta.IsSameTarget(m) {
+				return issue, nil
+			}
+		}
+		if resp.NextPage == 0 {
+			return nil, nil
+		}
+		if sentinel >= maxPages {
+			logrus.WithFields(logrus.Fields{
+				"program": "tfcmt",
+			}).WithField("maxPages", maxPages).Debug("gitlab.drift.findIssue: too many pages, something went wrong")
+			return nil, nil
+		}
+		opt.Page = resp.NextPage
+	}
+}
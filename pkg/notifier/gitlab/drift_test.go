@@ -0,0 +1,168 @@
+package gitlab
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hirosassa/tfcmt-gitlab/pkg/notifier"
+	gitlabmock "github.com/hirosassa/tfcmt-gitlab/pkg/notifier/gitlab/gen"
+	"github.com/hirosassa/tfcmt-gitlab/pkg/terraform"
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+	"go.uber.org/mock/gomock"
+)
+
+const driftOutput = `Note: Objects have changed outside of Terraform
+
+Terraform detected the following changes made outside of Terraform since the
+last "terraform apply" which may have affected this plan:
+
+  # null_resource.foo has been deleted
+  - resource "null_resource" "foo" {
+      - id = "1" -> null
+    }
+
+This is a refresh-only plan, so Terraform will not take any actions to undo
+these.`
+
+const noDriftOutput = "No changes. Your infrastructure still matches the configuration."
+
+func TestDriftNotify(t *testing.T) {
+	t.Parallel()
+	issueBody := func(target string) string {
+		body, err := (&Metadata{Program: metadataProgram, Command: commandDrift, Target: target}).Embed("drift")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return body
+	}
+	listOpt := &gitlab.ListProjectIssuesOptions{
+		ListOptions: gitlab.ListOptions{Page: 1, PerPage: listPerPage},
+		State:       gitlab.Ptr("opened"),
+		Labels:      &gitlab.LabelOptions{"drift"},
+	}
+	testCases := []struct {
+		name                string
+		createMockGitLabAPI func(ctrl *gomock.Controller) *gitlabmock.MockAPI
+		param               notifier.ParamExec
+		exitCode            int
+	}{
+		{
+			name: "open an issue on drift",
+			createMockGitLabAPI: func(ctrl *gomock.Controller) *gitlabmock.MockAPI {
+				api := gitlabmock.NewMockAPI(ctrl)
+				api.EXPECT().ListProjectIssues(listOpt).Return([]*gitlab.Issue{
+					{IID: 3, Description: issueBody("dev")},
+				}, &gitlab.Response{}, nil)
+				api.EXPECT().CreateIssue(gomock.Any()).DoAndReturn(
+					func(opt *gitlab.CreateIssueOptions, _ ...gitlab.RequestOptionFunc) (*gitlab.Issue, *gitlab.Response, error) {
+						if *opt.Title != "Drift detected (prod)" {
+							t.Errorf("title: %s", *opt.Title)
+						}
+						if !strings.Contains(*opt.Description, "null_resource.foo") {
+							t.Errorf("the drifted resource isn't found in the description: %s", *opt.Description)
+						}
+						if m := extractMetadata(*opt.Description); m == nil || m.Command != commandDrift || m.Target != "prod" {
+							t.Errorf("metadata: %+v", m)
+						}
+						if len(*opt.Labels) != 1 || (*opt.Labels)[0] != "drift" {
+							t.Errorf("labels: %v", *opt.Labels)
+						}
+						if len(*opt.AssigneeIDs) != 1 || (*opt.AssigneeIDs)[0] != 7 {
+							t.Errorf("assignees: %v", *opt.AssigneeIDs)
+						}
+						return &gitlab.Issue{IID: 4}, nil, nil
+					})
+				return api
+			},
+			param:    notifier.ParamExec{CombinedOutput: driftOutput},
+			exitCode: terraform.ExitPass,
+		},
+		{
+			name: "update the issue of the target on drift",
+			createMockGitLabAPI: func(ctrl *gomock.Controller) *gitlabmock.MockAPI {
+				api := gitlabmock.NewMockAPI(ctrl)
+				api.EXPECT().ListProjectIssues(listOpt).Return([]*gitlab.Issue{
+					{IID: 3, Description: issueBody("prod")},
+				}, &gitlab.Response{}, nil)
+				api.EXPECT().UpdateIssue(3, gomock.Any()).DoAndReturn(
+					func(_ int, opt *gitlab.UpdateIssueOptions, _ ...gitlab.RequestOptionFunc) (*gitlab.Issue, *gitlab.Response, error) {
+						if opt.StateEvent != nil {
+							t.Errorf("the issue mustn't be closed: %s", *opt.StateEvent)
+						}
+						if opt.AssigneeIDs != nil {
+							t.Errorf("the assignees mustn't be changed: %v", *opt.AssigneeIDs)
+						}
+						if !strings.Contains(*opt.Description, "null_resource.foo") {
+							t.Errorf("the drifted resource isn't found in the description: %s", *opt.Description)
+						}
+						return &gitlab.Issue{IID: 3}, nil, nil
+					})
+				return api
+			},
+			param:    notifier.ParamExec{CombinedOutput: driftOutput},
+			exitCode: terraform.ExitPass,
+		},
+		{
+			name: "close the issue when the drift disappears",
+			createMockGitLabAPI: func(ctrl *gomock.Controller) *gitlabmock.MockAPI {
+				api := gitlabmock.NewMockAPI(ctrl)
+				api.EXPECT().ListProjectIssues(listOpt).Return([]*gitlab.Issue{
+					{IID: 3, Description: issueBody("prod")},
+				}, &gitlab.Response{}, nil)
+				api.EXPECT().UpdateIssue(3, &gitlab.UpdateIssueOptions{
+					StateEvent: gitlab.Ptr("close"),
+				}).Return(&gitlab.Issue{IID: 3}, nil, nil)
+				return api
+			},
+			param:    notifier.ParamExec{CombinedOutput: noDriftOutput},
+			exitCode: terraform.ExitPass,
+		},
+		{
+			name: "no issue without drift",
+			createMockGitLabAPI: func(ctrl *gomock.Controller) *gitlabmock.MockAPI {
+				api := gitlabmock.NewMockAPI(ctrl)
+				api.EXPECT().ListProjectIssues(listOpt).Return(nil, &gitlab.Response{}, nil)
+				return api
+			},
+			param:    notifier.ParamExec{CombinedOutput: noDriftOutput},
+			exitCode: terraform.ExitPass,
+		},
+		{
+			name: "keep the issue on a plan error",
+			createMockGitLabAPI: func(ctrl *gomock.Controller) *gitlabmock.MockAPI {
+				return gitlabmock.NewMockAPI(ctrl)
+			},
+			param:    notifier.ParamExec{CombinedOutput: "Error: Invalid reference", ExitCode: 1},
+			exitCode: terraform.ExitFail,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+			mockCtrl := gomock.NewController(t)
+			cfg := newFakeConfig()
+			cfg.Parser = terraform.NewDriftParser()
+			cfg.Template = terraform.NewDriftTemplate("")
+			cfg.Vars = map[string]string{"target": "prod"}
+			cfg.Drift = DriftIssue{
+				Title:       "Drift detected (prod)",
+				Labels:      []string{"drift"},
+				AssigneeIDs: []int{7},
+			}
+			client, err := NewClient(cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			client.API = testCase.createMockGitLabAPI(mockCtrl)
+			exitCode, err := client.Drift.Notify(testCase.param)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if exitCode != testCase.exitCode {
+				t.Errorf("got exit code %d but want %d", exitCode, testCase.exitCode)
+			}
+		})
+	}
+}
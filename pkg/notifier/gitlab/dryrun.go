@@ -0,0 +1,231 @@
+package gitlab
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+// DryRunAPI is an API which prints the operations instead of calling GitLab API.
+// The read operations return empty results, so it works without a GitLab token.
+type DryRunAPI struct {
+	Writer io.Writer
+
+	mutex  sync.Mutex
+	lastID int64
+}
+
+func (d *DryRunAPI) printf(format string, a ...any) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	fmt.Fprintf(d.Writer, "[dry-run] "+format+"\n", a...)
+}
+
+func (d *DryRunAPI) printBody(header, body string) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	fmt.Fprintf(d.Writer, "[dry-run] %s\n%s\n%s\n", header, strings.TrimRight(body, "\n"), strings.Repeat("-", 80)) //nolint:gomnd
+}
+
+func (d *DryRunAPI) nextID() int64 {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.lastID++
+	return d.lastID
+}
+
+// CreateMergeRequestNote prints the body
+func (d *DryRunAPI) CreateMergeRequestNote(mergeRequest int, opt *gitlab.CreateMergeRequestNoteOptions, _ ...gitlab.RequestOptionFunc) (*gitlab.Note, *gitlab.Response, error) {
+	d.printBody(fmt.Sprintf("create a comment on the merge request !%d", mergeRequest), deref(opt.Body))
+	return &gitlab.Note{ID: d.nextID(), Body: deref(opt.Body)}, &gitlab.Response{}, nil
+}
+
+// DeleteMergeRequestNote prints the operation
+func (d *DryRunAPI) DeleteMergeRequestNote(mergeRequest, note int, _ ...gitlab.RequestOptionFunc) (*gitlab.Response, error) {
+	d.printf("delete the comment %d on the merge request !%d", note, mergeRequest)
+	return &gitlab.Response{}, nil
+}
+
+// UpdateMergeRequestNote prints the body
+func (d *DryRunAPI) UpdateMergeRequestNote(mergeRequest, note int, opt *gitlab.UpdateMergeRequestNoteOptions, _ ...gitlab.RequestOptionFunc) (*gitlab.Note, *gitlab.Response, error) {
+	d.printBody(fmt.Sprintf("update the comment %d on the merge request !%d", note, mergeRequest), deref(opt.Body))
+	return &gitlab.Note{ID: int64(note), Body: deref(opt.Body)}, &gitlab.Response{}, nil
+}
+
+// ListMergeRequestNotes returns no comment
+func (d *DryRunAPI) ListMergeRequestNotes(int, *gitlab.ListMergeRequestNotesOptions, ...gitlab.RequestOptionFunc) ([]*gitlab.Note, *gitlab.Response, error) {
+	return nil, &gitlab.Response{}, nil
+}
+
+// GetMergeRequest returns an empty merge request
+func (d *DryRunAPI) GetMergeRequest(mergeRequest int, _ *gitlab.GetMergeRequestsOptions, _ ...gitlab.RequestOptionFunc) (*gitlab.MergeRequest, *gitlab.Response, error) {
+	return &gitlab.MergeRequest{BasicMergeRequest: gitlab.BasicMergeRequest{IID: int64(mergeRequest)}}, &gitlab.Response{}, nil
+}
+
+// UpdateMergeRequest prints the operation
+func (d *DryRunAPI) UpdateMergeRequest(mergeRequest int, _ *gitlab.UpdateMergeRequestOptions, _ ...gitlab.RequestOptionFunc) (*gitlab.MergeRequest, *gitlab.Response, error) {
+	d.printf("update the merge request !%d", mergeRequest)
+	return &gitlab.MergeRequest{BasicMergeRequest: gitlab.BasicMergeRequest{IID: int64(mergeRequest)}}, &gitlab.Response{}, nil
+}
+
+// PostCommitComment prints the body
+func (d *DryRunAPI) PostCommitComment(sha string, opt *gitlab.PostCommitCommentOptions, _ ...gitlab.RequestOptionFunc) (*gitlab.CommitComment, *gitlab.Response, error) {
+	d.printBody("create a comment on the commit "+sha, deref(opt.Note))
+	return &gitlab.CommitComment{Note: deref(opt.Note)}, &gitlab.Response{}, nil
+}
+
+// AddMergeRequestLabels prints the operation
+func (d *DryRunAPI) AddMergeRequestLabels(labels *[]string, mergeRequest int) (gitlab.Labels, error) {
+	d.printf("add the labels %s to the merge request !%d", strings.Join(*labels, ", "), mergeRequest)
+	return *labels, nil
+}
+
+// RemoveMergeRequestLabels prints the operation
+func (d *DryRunAPI) RemoveMergeRequestLabels(labels *[]string, mergeRequest int) (gitlab.Labels, error) {
+	d.printf("remove the labels %s from the merge request !%d", strings.Join(*labels, ", "), mergeRequest)
+	return nil, nil
+}
+
+// ListMergeRequestLabels returns no label
+func (d *DryRunAPI) ListMergeRequestLabels(int, *gitlab.GetMergeRequestsOptions, ...gitlab.RequestOptionFunc) (gitlab.Labels, error) {
+	return nil, nil
+}
+
+// GetLabel returns the label without color
+func (d *DryRunAPI) GetLabel(labelName string, _ ...gitlab.RequestOptionFunc) (*gitlab.Label, *gitlab.Response, error) {
+	return &gitlab.Label{Name: labelName}, &gitlab.Response{}, nil
+}
+
+// UpdateLabel prints the operation
+func (d *DryRunAPI) UpdateLabel(opt *gitlab.UpdateLabelOptions, _ ...gitlab.RequestOptionFunc) (*gitlab.Label, *gitlab.Response, error) {
+	d.printf("change the color of the label %s to %s", deref(opt.Name), deref(opt.Color))
+	return &gitlab.Label{Name: deref(opt.Name), Color: deref(opt.Color)}, &gitlab.Response{}, nil
+}
+
+// GetCommit returns an empty commit
+func (d *DryRunAPI) GetCommit(sha string, _ ...gitlab.RequestOptionFunc) (*gitlab.Commit, *gitlab.Response, error) {
+	return &gitlab.Commit{ID: sha}, &gitlab.Response{}, nil
+}
+
+// ListMergeRequestsByCommit returns no merge request
+func (d *DryRunAPI) ListMergeRequestsByCommit(string, ...gitlab.RequestOptionFunc) ([]*gitlab.BasicMergeRequest, *gitlab.Response, error) {
+	return nil, &gitlab.Response{}, nil
+}
+
+// CreateMergeRequestDiscussion prints the body
+func (d *DryRunAPI) CreateMergeRequestDiscussion(mergeRequest int, opt *gitlab.CreateMergeRequestDiscussionOptions, _ ...gitlab.RequestOptionFunc) (*gitlab.Discussion, *gitlab.Response, error) {
+	d.printBody(fmt.Sprintf("create a discussion on the merge request !%d", mergeRequest), deref(opt.Body))
+	id := d.nextID()
+	return &gitlab.Discussion{
+		ID:    fmt.Sprintf("dry-run-%d", id),
+		Notes: []*gitlab.Note{{ID: id, Body: deref(opt.Body)}},
+	}, &gitlab.Response{}, nil
+}
+
+// UpdateMergeRequestDiscussionNote prints the body
+func (d *DryRunAPI) UpdateMergeRequestDiscussionNote(mergeRequest int, discussion string, note int, opt *gitlab.UpdateMergeRequestDiscussionNoteOptions, _ ...gitlab.RequestOptionFunc) (*gitlab.Note, *gitlab.Response, error) {
+	d.printBody(fmt.Sprintf("update the note %d of the discussion %s on the merge request !%d", note, discussion, mergeRequest), deref(opt.Body))
+	return &gitlab.Note{ID: int64(note), Body: deref(opt.Body)}, &gitlab.Response{}, nil
+}
+
+// ListMergeRequestDiscussions returns no discussion
+func (d *DryRunAPI) ListMergeRequestDiscussions(int, *gitlab.ListMergeRequestDiscussionsOptions, ...gitlab.RequestOptionFunc) ([]*gitlab.Discussion, *gitlab.Response, error) {
+	return nil, &gitlab.Response{}, nil
+}
+
+// ResolveMergeRequestDiscussion prints the operation
+func (d *DryRunAPI) ResolveMergeRequestDiscussion(mergeRequest int, discussion string, opt *gitlab.ResolveMergeRequestDiscussionOptions, _ ...gitlab.RequestOptionFunc) (*gitlab.Discussion, *gitlab.Response, error) {
+	action := "unresolve"
+	if deref(opt.Resolved) {
+		action = "resolve"
+	}
+	d.printf("%s the discussion %s on the merge request !%d", action, discussion, mergeRequest)
+	return &gitlab.Discussion{ID: discussion}, &gitlab.Response{}, nil
+}
+
+// AddMergeRequestDiscussionNote prints the body
+func (d *DryRunAPI) AddMergeRequestDiscussionNote(mergeRequest int, discussion string, opt *gitlab.AddMergeRequestDiscussionNoteOptions, _ ...gitlab.RequestOptionFunc) (*gitlab.Note, *gitlab.Response, error) {
+	d.printBody(fmt.Sprintf("reply to the discussion %s on the merge request !%d", discussion, mergeRequest), deref(opt.Body))
+	return &gitlab.Note{ID: d.nextID(), Body: deref(opt.Body)}, &gitlab.Response{}, nil
+}
+
+// CreateProjectSnippet prints the operation and returns a dummy URL
+func (d *DryRunAPI) CreateProjectSnippet(opt *gitlab.CreateProjectSnippetOptions, _ ...gitlab.RequestOptionFunc) (*gitlab.Snippet, *gitlab.Response, error) {
+	size := 0
+	if opt.Files != nil {
+		for _, f := range *opt.Files {
+			size += len(deref(f.Content))
+		}
+	}
+	d.printf("create a snippet %q (%d characters)", deref(opt.Title), size)
+	id := d.nextID()
+	return &gitlab.Snippet{ID: id, WebURL: fmt.Sprintf("https://gitlab.example.com/dry-run/-/snippets/%d", id)}, &gitlab.Response{}, nil
+}
+
+// ListMergeRequestApprovalRules returns no approval rule
+func (d *DryRunAPI) ListMergeRequestApprovalRules(int, ...gitlab.RequestOptionFunc) ([]*gitlab.MergeRequestApprovalRule, *gitlab.Response, error) {
+	return nil, &gitlab.Response{}, nil
+}
+
+// CreateMergeRequestApprovalRule prints the operation
+func (d *DryRunAPI) CreateMergeRequestApprovalRule(mergeRequest int, opt *gitlab.CreateMergeRequestApprovalRuleOptions, _ ...gitlab.RequestOptionFunc) (*gitlab.MergeRequestApprovalRule, *gitlab.Response, error) {
+	d.printf("create the approval rule %q requiring %d approvals (users: %v, groups: %v) on the merge request !%d",
+		deref(opt.Name), deref(opt.ApprovalsRequired), deref(opt.UserIDs), deref(opt.GroupIDs), mergeRequest)
+	return &gitlab.MergeRequestApprovalRule{ID: d.nextID(), Name: deref(opt.Name), ApprovalsRequired: deref(opt.ApprovalsRequired)}, &gitlab.Response{}, nil
+}
+
+// UpdateMergeRequestApprovalRule prints the operation
+func (d *DryRunAPI) UpdateMergeRequestApprovalRule(mergeRequest, approvalRule int, opt *gitlab.UpdateMergeRequestApprovalRuleOptions, _ ...gitlab.RequestOptionFunc) (*gitlab.MergeRequestApprovalRule, *gitlab.Response, error) {
+	d.printf("update the approval rule %d to require %d approvals (users: %v, groups: %v) on the merge request !%d",
+		approvalRule, deref(opt.ApprovalsRequired), deref(opt.UserIDs), deref(opt.GroupIDs), mergeRequest)
+	return &gitlab.MergeRequestApprovalRule{ID: int64(approvalRule), Name: deref(opt.Name), ApprovalsRequired: deref(opt.ApprovalsRequired)}, &gitlab.Response{}, nil
+}
+
+// DeleteMergeRequestApprovalRule prints the operation
+func (d *DryRunAPI) DeleteMergeRequestApprovalRule(mergeRequest, approvalRule int, _ ...gitlab.RequestOptionFunc) (*gitlab.Response, error) {
+	d.printf("delete the approval rule %d on the merge request !%d", approvalRule, mergeRequest)
+	return &gitlab.Response{}, nil
+}
+
+// SetCommitStatus prints the operation
+func (d *DryRunAPI) SetCommitStatus(sha string, opt *gitlab.SetCommitStatusOptions, _ ...gitlab.RequestOptionFunc) (*gitlab.CommitStatus, *gitlab.Response, error) {
+	d.printf("set the commit status %s of the commit %s to %s: %s", deref(opt.Name), sha, opt.State, deref(opt.Description))
+	return &gitlab.CommitStatus{ID: d.nextID(), SHA: sha, Name: deref(opt.Name), Status: string(opt.State)}, &gitlab.Response{}, nil
+}
+
+// ListProjectIssues returns no issue
+func (d *DryRunAPI) ListProjectIssues(*gitlab.ListProjectIssuesOptions, ...gitlab.RequestOptionFunc) ([]*gitlab.Issue, *gitlab.Response, error) {
+	return nil, &gitlab.Response{}, nil
+}
+
+// CreateIssue prints the description
+func (d *DryRunAPI) CreateIssue(opt *gitlab.CreateIssueOptions, _ ...gitlab.RequestOptionFunc) (*gitlab.Issue, *gitlab.Response, error) {
+	var labels gitlab.LabelOptions
+	if opt.Labels != nil {
+		labels = *opt.Labels
+	}
+	d.printBody(fmt.Sprintf("create an issue %q (labels: %v, assignees: %v)", deref(opt.Title), labels, deref(opt.AssigneeIDs)), deref(opt.Description))
+	id := d.nextID()
+	return &gitlab.Issue{ID: id, IID: id, Title: deref(opt.Title), Description: deref(opt.Description)}, &gitlab.Response{}, nil
+}
+
+// UpdateIssue prints the operation
+func (d *DryRunAPI) UpdateIssue(issue int, opt *gitlab.UpdateIssueOptions, _ ...gitlab.RequestOptionFunc) (*gitlab.Issue, *gitlab.Response, error) {
+	if deref(opt.StateEvent) == "close" {
+		d.printf("close the issue #%d", issue)
+	} else {
+		d.printBody(fmt.Sprintf("update the issue #%d", issue), deref(opt.Description))
+	}
+	return &gitlab.Issue{IID: int64(issue), Title: deref(opt.Title), Description: deref(opt.Description)}, &gitlab.Response{}, nil
+}
+
+func deref[T any](p *T) T {
+	var v T
+	if p != nil {
+		v = *p
+	}
+	return v
+}
@@ -0,0 +1,74 @@
+package gitlab
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/hirosassa/tfcmt-gitlab/pkg/notifier"
+)
+
+func TestNotifyDryRun(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		name      string
+		config    func() Config
+		paramExec notifier.ParamExec
+		exps      []string
+	}{
+		{
+			name: "the comment and the label operations are printed",
+			config: func() Config {
+				cfg := newFakeConfig()
+				cfg.Token = ""
+				cfg.ResultLabels = ResultLabels{
+					DestroyLabel:      "destroy",
+					DestroyLabelColor: "d9534f",
+				}
+				return cfg
+			},
+			paramExec: notifier.ParamExec{
+				CombinedOutput: "Plan: 0 to add, 0 to change, 1 to destroy.",
+				ExitCode:       0,
+			},
+			exps: []string{
+				"[dry-run] add the labels destroy to the merge request !1\n",
+				"[dry-run] change the color of the label destroy to d9534f\n",
+				"[dry-run] create a comment on the merge request !1\n\n## Plan Result\n",
+				"Plan: 0 to add, 0 to change, 1 to destroy.",
+			},
+		},
+		{
+			name: "a placeholder merge request is used without merge request and revision",
+			config: func() Config {
+				cfg := newFakeConfig()
+				cfg.Token = ""
+				cfg.MR = MergeRequest{}
+				return cfg
+			},
+			paramExec: notifier.ParamExec{
+				CombinedOutput: "Plan: 1 to add, 0 to change, 0 to destroy.",
+				ExitCode:       0,
+			},
+			exps: []string{
+				"[dry-run] create a comment on the merge request !1\n",
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+			buf := &bytes.Buffer{}
+			client := newDryRunClient(testCase.config(), buf)
+			if _, err := client.Notify.Notify(testCase.paramExec); err != nil {
+				t.Fatal(err)
+			}
+			for _, exp := range testCase.exps {
+				if !strings.Contains(buf.String(), exp) {
+					t.Errorf("the output doesn't contain %q:\n%s", exp, buf.String())
+				}
+			}
+		})
+	}
+}
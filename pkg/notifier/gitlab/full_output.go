@@ -0,0 +1,68 @@
+package gitlab
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+const (
+	// FullOutputSnippet uploads the full output to a project snippet
+	FullOutputSnippet = "snippet"
+	// FullOutputArtifact writes the full output to a file which is uploaded as a job artifact
+	FullOutputArtifact = "artifact"
+	// DefaultFullOutputPath is the default path of the file written with FullOutputArtifact
+	DefaultFullOutputPath = "tfcmt-full-output.txt"
+)
+
+// uploadFullOutput uploads the full output of the command and returns the URL to it.
+// It returns an empty string if uploading the full output is disabled.
+func (g *NotifyService) uploadFullOutput(command, output string) (string, error) {
+	cfg := g.client.Config
+	if output == "" {
+		return "", nil
+	}
+	switch cfg.FullOutput {
+	case FullOutputSnippet:
+		title := "tfcmt-gitlab " + command + " result"
+		if target := cfg.Vars["target"]; target != "" {
+			title += " (" + target + ")"
+		}
+		if cfg.MR.IsNumber() {
+			title += fmt.Sprintf(" !%d", cfg.MR.Number)
+		}
+		snippet, _, err := g.client.API.CreateProjectSnippet(&gitlab.CreateProjectSnippetOptions{
+			Title:      gitlab.Ptr(title),
+			Visibility: gitlab.Ptr(gitlab.PrivateVisibility),
+			Files: &[]*gitlab.CreateSnippetFileOptions{
+				{
+					FilePath: gitlab.Ptr(command + ".txt"),
+					Content:  gitlab.Ptr(output),
+				},
+			},
+		})
+		if err != nil {
+			return "", fmt.Errorf("create a snippet: %w", err)
+		}
+		return snippet.WebURL, nil
+	case FullOutputArtifact:
+		if cfg.CI == "" {
+			return "", errors.New("the link to the CI job is required to link the artifact")
+		}
+		path := cfg.FullOutputPath
+		if path == "" {
+			path = DefaultFullOutputPath
+		}
+		if err := os.WriteFile(path, []byte(output), 0o600); err != nil { //nolint:gomnd
+			return "", fmt.Errorf("write the full output to a file %s: %w", path, err)
+		}
+		// https://docs.gitlab.com/ee/ci/jobs/job_artifacts.html#link-to-job-artifacts-in-the-merge-request-ui
+		return strings.TrimSuffix(cfg.CI, "/") + "/artifacts/file/" + filepath.ToSlash(filepath.Clean(path)), nil
+	default:
+		return "", nil
+	}
+}
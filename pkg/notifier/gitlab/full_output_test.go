@@ -0,0 +1,98 @@
+package gitlab
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	gitlabmock "github.com/hirosassa/tfcmt-gitlab/pkg/notifier/gitlab/gen"
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+	"go.uber.org/mock/gomock"
+)
+
+func TestNotifyUploadFullOutputSnippet(t *testing.T) {
+	t.Parallel()
+	cfg := newFakeConfig()
+	cfg.FullOutput = FullOutputSnippet
+	cfg.Vars = map[string]string{"target": "foo"}
+	client, err := NewClient(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	api := gitlabmock.NewMockAPI(mockCtrl)
+	api.EXPECT().CreateProjectSnippet(&gitlab.CreateProjectSnippetOptions{
+		Title:      gitlab.Ptr("tfcmt-gitlab plan result (foo) !1"),
+		Visibility: gitlab.Ptr(gitlab.PrivateVisibility),
+		Files: &[]*gitlab.CreateSnippetFileOptions{
+			{FilePath: gitlab.Ptr("plan.txt"), Content: gitlab.Ptr("output")},
+		},
+	}).Return(&gitlab.Snippet{WebURL: "https://gitlab.example.com/owner/repo/-/snippets/1"}, nil, nil)
+	client.API = api
+
+	url, err := client.Notify.uploadFullOutput(commandPlan, "output")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if url != "https://gitlab.example.com/owner/repo/-/snippets/1" {
+		t.Errorf("unexpected URL: %s", url)
+	}
+}
+
+func TestNotifyUploadFullOutputArtifact(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "output.txt")
+	testCases := []struct {
+		name string
+		ci   string
+		url  string
+		ok   bool
+	}{
+		{
+			name: "write the file and link it",
+			ci:   "https://gitlab.example.com/owner/repo/-/jobs/1/",
+			url:  "https://gitlab.example.com/owner/repo/-/jobs/1/artifacts/file/" + filepath.ToSlash(path),
+			ok:   true,
+		},
+		{
+			name: "the link to the job is missing",
+		},
+	}
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+			cfg := newFakeConfig()
+			cfg.FullOutput = FullOutputArtifact
+			cfg.FullOutputPath = path
+			cfg.CI = testCase.ci
+			client, err := NewClient(cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			url, err := client.Notify.uploadFullOutput(commandApply, "output")
+			if !testCase.ok {
+				if err == nil {
+					t.Fatal("error should be returned")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if url != testCase.url {
+				t.Errorf("wanted %s, got %s", testCase.url, url)
+			}
+			b, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(b) != "output" {
+				t.Errorf("unexpected content: %s", string(b))
+			}
+		})
+	}
+}
@@ -20,6 +20,7 @@ import (
 type MockAPI struct {
 	ctrl     *gomock.Controller
 	recorder *MockAPIMockRecorder
+	isgomock struct{}
 }
 
 // MockAPIMockRecorder is the mock recorder for MockAPI.
@@ -39,6 +40,27 @@ func (m *MockAPI) EXPECT() *MockAPIMockRecorder {
 	return m.recorder
 }
 
+// AddMergeRequestDiscussionNote mocks base method.
+func (m *MockAPI) AddMergeRequestDiscussionNote(mergeRequest int, discussion string, opt *gitlab.AddMergeRequestDiscussionNoteOptions, options ...gitlab.RequestOptionFunc) (*gitlab.Note, *gitlab.Response, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{mergeRequest, discussion, opt}
+	for _, a := range options {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "AddMergeRequestDiscussionNote", varargs...)
+	ret0, _ := ret[0].(*gitlab.Note)
+	ret1, _ := ret[1].(*gitlab.Response)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// AddMergeRequestDiscussionNote indicates an expected call of AddMergeRequestDiscussionNote.
+func (mr *MockAPIMockRecorder) AddMergeRequestDiscussionNote(mergeRequest, discussion, opt any, options ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{mergeRequest, discussion, opt}, options...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddMergeRequestDiscussionNote", reflect.TypeOf((*MockAPI)(nil).AddMergeRequestDiscussionNote), varargs...)
+}
+
 // AddMergeRequestLabels mocks base method.
 func (m *MockAPI) AddMergeRequestLabels(labels *[]string, mergeRequest int) (gitlab.Labels, error) {
 	m.ctrl.T.Helper()
@@ -54,6 +76,69 @@ func (mr *MockAPIMockRecorder) AddMergeRequestLabels(labels, mergeRequest any) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddMergeRequestLabels", reflect.TypeOf((*MockAPI)(nil).AddMergeRequestLabels), labels, mergeRequest)
 }
 
+// CreateIssue mocks base method.
+func (m *MockAPI) CreateIssue(opt *gitlab.CreateIssueOptions, options ...gitlab.RequestOptionFunc) (*gitlab.Issue, *gitlab.Response, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{opt}
+	for _, a := range options {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "CreateIssue", varargs...)
+	ret0, _ := ret[0].(*gitlab.Issue)
+	ret1, _ := ret[1].(*gitlab.Response)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// CreateIssue indicates an expected call of CreateIssue.
+func (mr *MockAPIMockRecorder) CreateIssue(opt any, options ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{opt}, options...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateIssue", reflect.TypeOf((*MockAPI)(nil).CreateIssue), varargs...)
+}
+
+// CreateMergeRequestApprovalRule mocks base method.
+func (m *MockAPI) CreateMergeRequestApprovalRule(mergeRequest int, opt *gitlab.CreateMergeRequestApprovalRuleOptions, options ...gitlab.RequestOptionFunc) (*gitlab.MergeRequestApprovalRule, *gitlab.Response, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{mergeRequest, opt}
+	for _, a := range options {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "CreateMergeRequestApprovalRule", varargs...)
+	ret0, _ := ret[0].(*gitlab.MergeRequestApprovalRule)
+	ret1, _ := ret[1].(*gitlab.Response)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// CreateMergeRequestApprovalRule indicates an expected call of CreateMergeRequestApprovalRule.
+func (mr *MockAPIMockRecorder) CreateMergeRequestApprovalRule(mergeRequest, opt any, options ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{mergeRequest, opt}, options...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateMergeRequestApprovalRule", reflect.TypeOf((*MockAPI)(nil).CreateMergeRequestApprovalRule), varargs...)
+}
+
+// CreateMergeRequestDiscussion mocks base method.
+func (m *MockAPI) CreateMergeRequestDiscussion(mergeRequest int, opt *gitlab.CreateMergeRequestDiscussionOptions, options ...gitlab.RequestOptionFunc) (*gitlab.Discussion, *gitlab.Response, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{mergeRequest, opt}
+	for _, a := range options {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "CreateMergeRequestDiscussion", varargs...)
+	ret0, _ := ret[0].(*gitlab.Discussion)
+	ret1, _ := ret[1].(*gitlab.Response)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// CreateMergeRequestDiscussion indicates an expected call of CreateMergeRequestDiscussion.
+func (mr *MockAPIMockRecorder) CreateMergeRequestDiscussion(mergeRequest, opt any, options ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{mergeRequest, opt}, options...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateMergeRequestDiscussion", reflect.TypeOf((*MockAPI)(nil).CreateMergeRequestDiscussion), varargs...)
+}
+
 // CreateMergeRequestNote mocks base method.
 func (m *MockAPI) CreateMergeRequestNote(mergeRequest int, opt *gitlab.CreateMergeRequestNoteOptions, options ...gitlab.RequestOptionFunc) (*gitlab.Note, *gitlab.Response, error) {
 	m.ctrl.T.Helper()
@@ -75,6 +160,67 @@ func (mr *MockAPIMockRecorder) CreateMergeRequestNote(mergeRequest, opt any, opt
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateMergeRequestNote", reflect.TypeOf((*MockAPI)(nil).CreateMergeRequestNote), varargs...)
 }
 
+// CreateProjectSnippet mocks base method.
+func (m *MockAPI) CreateProjectSnippet(opt *gitlab.CreateProjectSnippetOptions, options ...gitlab.RequestOptionFunc) (*gitlab.Snippet, *gitlab.Response, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{opt}
+	for _, a := range options {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "CreateProjectSnippet", varargs...)
+	ret0, _ := ret[0].(*gitlab.Snippet)
+	ret1, _ := ret[1].(*gitlab.Response)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// CreateProjectSnippet indicates an expected call of CreateProjectSnippet.
+func (mr *MockAPIMockRecorder) CreateProjectSnippet(opt any, options ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{opt}, options...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateProjectSnippet", reflect.TypeOf((*MockAPI)(nil).CreateProjectSnippet), varargs...)
+}
+
+// DeleteMergeRequestApprovalRule mocks base method.
+func (m *MockAPI) DeleteMergeRequestApprovalRule(mergeRequest, approvalRule int, options ...gitlab.RequestOptionFunc) (*gitlab.Response, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{mergeRequest, approvalRule}
+	for _, a := range options {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "DeleteMergeRequestApprovalRule", varargs...)
+	ret0, _ := ret[0].(*gitlab.Response)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteMergeRequestApprovalRule indicates an expected call of DeleteMergeRequestApprovalRule.
+func (mr *MockAPIMockRecorder) DeleteMergeRequestApprovalRule(mergeRequest, approvalRule any, options ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{mergeRequest, approvalRule}, options...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteMergeRequestApprovalRule", reflect.TypeOf((*MockAPI)(nil).DeleteMergeRequestApprovalRule), varargs...)
+}
+
+// DeleteMergeRequestNote mocks base method.
+func (m *MockAPI) DeleteMergeRequestNote(mergeRequest, note int, options ...gitlab.RequestOptionFunc) (*gitlab.Response, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{mergeRequest, note}
+	for _, a := range options {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "DeleteMergeRequestNote", varargs...)
+	ret0, _ := ret[0].(*gitlab.Response)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteMergeRequestNote indicates an expected call of DeleteMergeRequestNote.
+func (mr *MockAPIMockRecorder) DeleteMergeRequestNote(mergeRequest, note any, options ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{mergeRequest, note}, options...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteMergeRequestNote", reflect.TypeOf((*MockAPI)(nil).DeleteMergeRequestNote), varargs...)
+}
+
 // GetCommit mocks base method.
 func (m *MockAPI) GetCommit(sha string, options ...gitlab.RequestOptionFunc) (*gitlab.Commit, *gitlab.Response, error) {
 	m.ctrl.T.Helper()
@@ -138,6 +284,48 @@ func (mr *MockAPIMockRecorder) GetMergeRequest(mergeRequest, opt any, options ..
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMergeRequest", reflect.TypeOf((*MockAPI)(nil).GetMergeRequest), varargs...)
 }
 
+// ListMergeRequestApprovalRules mocks base method.
+func (m *MockAPI) ListMergeRequestApprovalRules(mergeRequest int, options ...gitlab.RequestOptionFunc) ([]*gitlab.MergeRequestApprovalRule, *gitlab.Response, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{mergeRequest}
+	for _, a := range options {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ListMergeRequestApprovalRules", varargs...)
+	ret0, _ := ret[0].([]*gitlab.MergeRequestApprovalRule)
+	ret1, _ := ret[1].(*gitlab.Response)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListMergeRequestApprovalRules indicates an expected call of ListMergeRequestApprovalRules.
+func (mr *MockAPIMockRecorder) ListMergeRequestApprovalRules(mergeRequest any, options ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{mergeRequest}, options...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListMergeRequestApprovalRules", reflect.TypeOf((*MockAPI)(nil).ListMergeRequestApprovalRules), varargs...)
+}
+
+// ListMergeRequestDiscussions mocks base method.
+func (m *MockAPI) ListMergeRequestDiscussions(mergeRequest int, opt *gitlab.ListMergeRequestDiscussionsOptions, options ...gitlab.RequestOptionFunc) ([]*gitlab.Discussion, *gitlab.Response, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{mergeRequest, opt}
+	for _, a := range options {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ListMergeRequestDiscussions", varargs...)
+	ret0, _ := ret[0].([]*gitlab.Discussion)
+	ret1, _ := ret[1].(*gitlab.Response)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListMergeRequestDiscussions indicates an expected call of ListMergeRequestDiscussions.
+func (mr *MockAPIMockRecorder) ListMergeRequestDiscussions(mergeRequest, opt any, options ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{mergeRequest, opt}, options...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListMergeRequestDiscussions", reflect.TypeOf((*MockAPI)(nil).ListMergeRequestDiscussions), varargs...)
+}
+
 // ListMergeRequestLabels mocks base method.
 func (m *MockAPI) ListMergeRequestLabels(mergeRequest int, opt *gitlab.GetMergeRequestsOptions, options ...gitlab.RequestOptionFunc) (gitlab.Labels, error) {
 	m.ctrl.T.Helper()
@@ -200,6 +388,27 @@ func (mr *MockAPIMockRecorder) ListMergeRequestsByCommit(sha any, options ...any
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListMergeRequestsByCommit", reflect.TypeOf((*MockAPI)(nil).ListMergeRequestsByCommit), varargs...)
 }
 
+// ListProjectIssues mocks base method.
+func (m *MockAPI) ListProjectIssues(opt *gitlab.ListProjectIssuesOptions, options ...gitlab.RequestOptionFunc) ([]*gitlab.Issue, *gitlab.Response, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{opt}
+	for _, a := range options {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ListProjectIssues", varargs...)
+	ret0, _ := ret[0].([]*gitlab.Issue)
+	ret1, _ := ret[1].(*gitlab.Response)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListProjectIssues indicates an expected call of ListProjectIssues.
+func (mr *MockAPIMockRecorder) ListProjectIssues(opt any, options ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{opt}, options...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListProjectIssues", reflect.TypeOf((*MockAPI)(nil).ListProjectIssues), varargs...)
+}
+
 // PostCommitComment mocks base method.
 func (m *MockAPI) PostCommitComment(sha string, opt *gitlab.PostCommitCommentOptions, options ...gitlab.RequestOptionFunc) (*gitlab.CommitComment, *gitlab.Response, error) {
 	m.ctrl.T.Helper()
@@ -236,6 +445,69 @@ func (mr *MockAPIMockRecorder) RemoveMergeRequestLabels(labels, mergeRequest any
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveMergeRequestLabels", reflect.TypeOf((*MockAPI)(nil).RemoveMergeRequestLabels), labels, mergeRequest)
 }
 
+// ResolveMergeRequestDiscussion mocks base method.
+func (m *MockAPI) ResolveMergeRequestDiscussion(mergeRequest int, discussion string, opt *gitlab.ResolveMergeRequestDiscussionOptions, options ...gitlab.RequestOptionFunc) (*gitlab.Discussion, *gitlab.Response, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{mergeRequest, discussion, opt}
+	for _, a := range options {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ResolveMergeRequestDiscussion", varargs...)
+	ret0, _ := ret[0].(*gitlab.Discussion)
+	ret1, _ := ret[1].(*gitlab.Response)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ResolveMergeRequestDiscussion indicates an expected call of ResolveMergeRequestDiscussion.
+func (mr *MockAPIMockRecorder) ResolveMergeRequestDiscussion(mergeRequest, discussion, opt any, options ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{mergeRequest, discussion, opt}, options...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResolveMergeRequestDiscussion", reflect.TypeOf((*MockAPI)(nil).ResolveMergeRequestDiscussion), varargs...)
+}
+
+// SetCommitStatus mocks base method.
+func (m *MockAPI) SetCommitStatus(sha string, opt *gitlab.SetCommitStatusOptions, options ...gitlab.RequestOptionFunc) (*gitlab.CommitStatus, *gitlab.Response, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{sha, opt}
+	for _, a := range options {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "SetCommitStatus", varargs...)
+	ret0, _ := ret[0].(*gitlab.CommitStatus)
+	ret1, _ := ret[1].(*gitlab.Response)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// SetCommitStatus indicates an expected call of SetCommitStatus.
+func (mr *MockAPIMockRecorder) SetCommitStatus(sha, opt any, options ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{sha, opt}, options...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetCommitStatus", reflect.TypeOf((*MockAPI)(nil).SetCommitStatus), varargs...)
+}
+
+// UpdateIssue mocks base method.
+func (m *MockAPI) UpdateIssue(issue int, opt *gitlab.UpdateIssueOptions, options ...gitlab.RequestOptionFunc) (*gitlab.Issue, *gitlab.Response, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{issue, opt}
+	for _, a := range options {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "UpdateIssue", varargs...)
+	ret0, _ := ret[0].(*gitlab.Issue)
+	ret1, _ := ret[1].(*gitlab.Response)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// UpdateIssue indicates an expected call of UpdateIssue.
+func (mr *MockAPIMockRecorder) UpdateIssue(issue, opt any, options ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{issue, opt}, options...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateIssue", reflect.TypeOf((*MockAPI)(nil).UpdateIssue), varargs...)
+}
+
 // UpdateLabel mocks base method.
 func (m *MockAPI) UpdateLabel(opt *gitlab.UpdateLabelOptions, options ...gitlab.RequestOptionFunc) (*gitlab.Label, *gitlab.Response, error) {
 	m.ctrl.T.Helper()
@@ -278,6 +550,48 @@ func (mr *MockAPIMockRecorder) UpdateMergeRequest(mergeRequest, opt any, options
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateMergeRequest", reflect.TypeOf((*MockAPI)(nil).UpdateMergeRequest), varargs...)
 }
 
+// UpdateMergeRequestApprovalRule mocks base method.
+func (m *MockAPI) UpdateMergeRequestApprovalRule(mergeRequest, approvalRule int, opt *gitlab.UpdateMergeRequestApprovalRuleOptions, options ...gitlab.RequestOptionFunc) (*gitlab.MergeRequestApprovalRule, *gitlab.Response, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{mergeRequest, approvalRule, opt}
+	for _, a := range options {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "UpdateMergeRequestApprovalRule", varargs...)
+	ret0, _ := ret[0].(*gitlab.MergeRequestApprovalRule)
+	ret1, _ := ret[1].(*gitlab.Response)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// UpdateMergeRequestApprovalRule indicates an expected call of UpdateMergeRequestApprovalRule.
+func (mr *MockAPIMockRecorder) UpdateMergeRequestApprovalRule(mergeRequest, approvalRule, opt any, options ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{mergeRequest, approvalRule, opt}, options...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateMergeRequestApprovalRule", reflect.TypeOf((*MockAPI)(nil).UpdateMergeRequestApprovalRule), varargs...)
+}
+
+// UpdateMergeRequestDiscussionNote mocks base method.
+func (m *MockAPI) UpdateMergeRequestDiscussionNote(mergeRequest int, discussion string, note int, opt *gitlab.UpdateMergeRequestDiscussionNoteOptions, options ...gitlab.RequestOptionFunc) (*gitlab.Note, *gitlab.Response, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{mergeRequest, discussion, note, opt}
+	for _, a := range options {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "UpdateMergeRequestDiscussionNote", varargs...)
+	ret0, _ := ret[0].(*gitlab.Note)
+	ret1, _ := ret[1].(*gitlab.Response)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// UpdateMergeRequestDiscussionNote indicates an expected call of UpdateMergeRequestDiscussionNote.
+func (mr *MockAPIMockRecorder) UpdateMergeRequestDiscussionNote(mergeRequest, discussion, note, opt any, options ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{mergeRequest, discussion, note, opt}, options...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateMergeRequestDiscussionNote", reflect.TypeOf((*MockAPI)(nil).UpdateMergeRequestDiscussionNote), varargs...)
+}
+
 // UpdateMergeRequestNote mocks base method.
 func (m *MockAPI) UpdateMergeRequestNote(mergeRequest, note int, opt *gitlab.UpdateMergeRequestNoteOptions, options ...gitlab.RequestOptionFunc) (*gitlab.Note, *gitlab.Response, error) {
 	m.ctrl.T.Helper()
@@ -9,6 +9,7 @@ import (
 // API is GitLab API interface
 type API interface {
 	CreateMergeRequestNote(mergeRequest int, opt *gitlab.CreateMergeRequestNoteOptions, options ...gitlab.RequestOptionFunc) (*gitlab.Note, *gitlab.Response, error)
+	DeleteMergeRequestNote(mergeRequest, note int, options ...gitlab.RequestOptionFunc) (*gitlab.Response, error)
 	UpdateMergeRequestNote(mergeRequest, note int, opt *gitlab.UpdateMergeRequestNoteOptions, options ...gitlab.RequestOptionFunc) (*gitlab.Note, *gitlab.Response, error)
 	ListMergeRequestNotes(mergeRequest int, opt *gitlab.ListMergeRequestNotesOptions, options ...gitlab.RequestOptionFunc) ([]*gitlab.Note, *gitlab.Response, error)
 	GetMergeRequest(mergeRequest int, opt *gitlab.GetMergeRequestsOptions, options ...gitlab.RequestOptionFunc) (*gitlab.MergeRequest, *gitlab.Response, error)
@@ -21,6 +22,20 @@ type API interface {
 	UpdateLabel(opt *gitlab.UpdateLabelOptions, options ...gitlab.RequestOptionFunc) (*gitlab.Label, *gitlab.Response, error)
 	GetCommit(sha string, options ...gitlab.RequestOptionFunc) (*gitlab.Commit, *gitlab.Response, error)
 	ListMergeRequestsByCommit(sha string, options ...gitlab.RequestOptionFunc) ([]*gitlab.BasicMergeRequest, *gitlab.Response, error)
+	CreateMergeRequestDiscussion(mergeRequest int, opt *gitlab.CreateMergeRequestDiscussionOptions, options ...gitlab.RequestOptionFunc) (*gitlab.Discussion, *gitlab.Response, error)
+	UpdateMergeRequestDiscussionNote(mergeRequest int, discussion string, note int, opt *gitlab.UpdateMergeRequestDiscussionNoteOptions, options ...gitlab.RequestOptionFunc) (*gitlab.Note, *gitlab.Response, error)
+	ListMergeRequestDiscussions(mergeRequest int, opt *gitlab.ListMergeRequestDiscussionsOptions, options ...gitlab.RequestOptionFunc) ([]*gitlab.Discussion, *gitlab.Response, error)
+	ResolveMergeRequestDiscussion(mergeRequest int, discussion string, opt *gitlab.ResolveMergeRequestDiscussionOptions, options ...gitlab.RequestOptionFunc) (*gitlab.Discussion, *gitlab.Response, error)
+	AddMergeRequestDiscussionNote(mergeRequest int, discussion string, opt *gitlab.AddMergeRequestDiscussionNoteOptions, options ...gitlab.RequestOptionFunc) (*gitlab.Note, *gitlab.Response, error)
+	CreateProjectSnippet(opt *gitlab.CreateProjectSnippetOptions, options ...gitlab.RequestOptionFunc) (*gitlab.Snippet, *gitlab.Response, error)
+	ListMergeRequestApprovalRules(mergeRequest int, options ...gitlab.RequestOptionFunc) ([]*gitlab.MergeRequestApprovalRule, *gitlab.Response, error)
+	CreateMergeRequestApprovalRule(mergeRequest int, opt *gitlab.CreateMergeRequestApprovalRuleOptions, options ...gitlab.RequestOptionFunc) (*gitlab.MergeRequestApprovalRule, *gitlab.Response, error)
+	UpdateMergeRequestApprovalRule(mergeRequest, approvalRule int, opt *gitlab.UpdateMergeRequestApprovalRuleOptions, options ...gitlab.RequestOptionFunc) (*gitlab.MergeRequestApprovalRule, *gitlab.Response, error)
+	DeleteMergeRequestApprovalRule(mergeRequest, approvalRule int, options ...gitlab.RequestOptionFunc) (*gitlab.Response, error)
+	SetCommitStatus(sha string, opt *gitlab.SetCommitStatusOptions, options ...gitlab.RequestOptionFunc) (*gitlab.CommitStatus, *gitlab.Response, error)
+	ListProjectIssues(opt *gitlab.ListProjectIssuesOptions, options ...gitlab.RequestOptionFunc) ([]*gitlab.Issue, *gitlab.Response, error)
+	CreateIssue(opt *gitlab.CreateIssueOptions, options ...gitlab.RequestOptionFunc) (*gitlab.Issue, *gitlab.Response, error)
+	UpdateIssue(issue int, opt *gitlab.UpdateIssueOptions, options ...gitlab.RequestOptionFunc) (*gitlab.Issue, *gitlab.Response, error)
 }
 
 // GitLab represents the attribute information necessary for requesting GitLab API
@@ -34,6 +49,11 @@ func (g *GitLab) CreateMergeRequestNote(mergeRequest int, opt *gitlab.CreateMerg
 	return g.Notes.CreateMergeRequestNote(fmt.Sprintf("%s/%s", g.namespace, g.project), int64(mergeRequest), opt, options...)
 }
 
+// DeleteMergeRequestNote is a wrapper of NotesService.DeleteMergeRequestNote
+func (g *GitLab) DeleteMergeRequestNote(mergeRequest, note int, options ...gitlab.RequestOptionFunc) (*gitlab.Response, error) {
+	return g.Notes.DeleteMergeRequestNote(fmt.Sprintf("%s/%s", g.namespace, g.project), int64(mergeRequest), int64(note), options...)
+}
+
 // UpdateMergeRequestNote is a wrapper of NotesService.UpdateMergeRequestNote
 func (g *GitLab) UpdateMergeRequestNote(mergeRequest, note int, opt *gitlab.UpdateMergeRequestNoteOptions, options ...gitlab.RequestOptionFunc) (*gitlab.Note, *gitlab.Response, error) {
 	return g.Notes.UpdateMergeRequestNote(fmt.Sprintf("%s/%s", g.namespace, g.project), int64(mergeRequest), int64(note), opt, options...)
@@ -114,3 +134,73 @@ func (g *GitLab) GetCommit(sha string, options ...gitlab.RequestOptionFunc) (*gi
 func (g *GitLab) ListMergeRequestsByCommit(sha string, options ...gitlab.RequestOptionFunc) ([]*gitlab.BasicMergeRequest, *gitlab.Response, error) {
 	return g.Commits.ListMergeRequestsByCommit(fmt.Sprintf("%s/%s", g.namespace, g.project), sha, options...)
 }
+
+// CreateMergeRequestDiscussion is a wrapper of DiscussionsService.CreateMergeRequestDiscussion
+func (g *GitLab) CreateMergeRequestDiscussion(mergeRequest int, opt *gitlab.CreateMergeRequestDiscussionOptions, options ...gitlab.RequestOptionFunc) (*gitlab.Discussion, *gitlab.Response, error) {
+	return g.Discussions.CreateMergeRequestDiscussion(fmt.Sprintf("%s/%s", g.namespace, g.project), int64(mergeRequest), opt, options...)
+}
+
+// UpdateMergeRequestDiscussionNote is a wrapper of DiscussionsService.UpdateMergeRequestDiscussionNote
+func (g *GitLab) UpdateMergeRequestDiscussionNote(mergeRequest int, discussion string, note int, opt *gitlab.UpdateMergeRequestDiscussionNoteOptions, options ...gitlab.RequestOptionFunc) (*gitlab.Note, *gitlab.Response, error) {
+	return g.Discussions.UpdateMergeRequestDiscussionNote(fmt.Sprintf("%s/%s", g.namespace, g.project), int64(mergeRequest), discussion, int64(note), opt, options...)
+}
+
+// ListMergeRequestDiscussions is a wrapper of DiscussionsService.ListMergeRequestDiscussions
+func (g *GitLab) ListMergeRequestDiscussions(mergeRequest int, opt *gitlab.ListMergeRequestDiscussionsOptions, options ...gitlab.RequestOptionFunc) ([]*gitlab.Discussion, *gitlab.Response, error) {
+	return g.Discussions.ListMergeRequestDiscussions(fmt.Sprintf("%s/%s", g.namespace, g.project), int64(mergeRequest), opt, options...)
+}
+
+// ResolveMergeRequestDiscussion is a wrapper of DiscussionsService.ResolveMergeRequestDiscussion
+func (g *GitLab) ResolveMergeRequestDiscussion(mergeRequest int, discussion string, opt *gitlab.ResolveMergeRequestDiscussionOptions, options ...gitlab.RequestOptionFunc) (*gitlab.Discussion, *gitlab.Response, error) {
+	return g.Discussions.ResolveMergeRequestDiscussion(fmt.Sprintf("%s/%s", g.namespace, g.project), int64(mergeRequest), discussion, opt, options...)
+}
+
+// AddMergeRequestDiscussionNote is a wrapper of DiscussionsService.AddMergeRequestDiscussionNote
+func (g *GitLab) AddMergeRequestDiscussionNote(mergeRequest int, discussion string, opt *gitlab.AddMergeRequestDiscussionNoteOptions, options ...gitlab.RequestOptionFunc) (*gitlab.Note, *gitlab.Response, error) {
+	return g.Discussions.AddMergeRequestDiscussionNote(fmt.Sprintf("%s/%s", g.namespace, g.project), int64(mergeRequest), discussion, opt, options...)
+}
+
+// CreateProjectSnippet is a wrapper of ProjectSnippetsService.CreateSnippet
+func (g *GitLab) CreateProjectSnippet(opt *gitlab.CreateProjectSnippetOptions, options ...gitlab.RequestOptionFunc) (*gitlab.Snippet, *gitlab.Response, error) {
+	return g.ProjectSnippets.CreateSnippet(fmt.Sprintf("%s/%s", g.namespace, g.project), opt, options...)
+}
+
+// ListMergeRequestApprovalRules is a wrapper of MergeRequestApprovalsService.GetApprovalRules
+func (g *GitLab) ListMergeRequestApprovalRules(mergeRequest int, options ...gitlab.RequestOptionFunc) ([]*gitlab.MergeRequestApprovalRule, *gitlab.Response, error) {
+	return g.MergeRequestApprovals.GetApprovalRules(fmt.Sprintf("%s/%s", g.namespace, g.project), int64(mergeRequest), options...)
+}
+
+// CreateMergeRequestApprovalRule is a wrapper of MergeRequestApprovalsService.CreateApprovalRule
+func (g *GitLab) CreateMergeRequestApprovalRule(mergeRequest int, opt *gitlab.CreateMergeRequestApprovalRuleOptions, options ...gitlab.RequestOptionFunc) (*gitlab.MergeRequestApprovalRule, *gitlab.Response, error) {
+	return g.MergeRequestApprovals.CreateApprovalRule(fmt.Sprintf("%s/%s", g.namespace, g.project), int64(mergeRequest), opt, options...)
+}
+
+// UpdateMergeRequestApprovalRule is a wrapper of MergeRequestApprovalsService.UpdateApprovalRule
+func (g *GitLab) UpdateMergeRequestApprovalRule(mergeRequest, approvalRule int, opt *gitlab.UpdateMergeRequestApprovalRuleOptions, options ...gitlab.RequestOptionFunc) (*gitlab.MergeRequestApprovalRule, *gitlab.Response, error) {
+	return g.MergeRequestApprovals.UpdateApprovalRule(fmt.Sprintf("%s/%s", g.namespace, g.project), int64(mergeRequest), int64(approvalRule), opt, options...)
+}
+
+// DeleteMergeRequestApprovalRule is a wrapper of MergeRequestApprovalsService.DeleteApprovalRule
+func (g *GitLab) DeleteMergeRequestApprovalRule(mergeRequest, approvalRule int, options ...gitlab.RequestOptionFunc) (*gitlab.Response, error) {
+	return g.MergeRequestApprovals.DeleteApprovalRule(fmt.Sprintf("%s/%s", g.namespace, g.project), int64(mergeRequest), int64(approvalRule), options...)
+}
+
+// SetCommitStatus is a wrapper of CommitsService.SetCommitStatus
+func (g *GitLab) SetCommitStatus(sha string, opt *gitlab.SetCommitStatusOptions, options ...gitlab.RequestOptionFunc) (*gitlab.CommitStatus, *gitlab.Response, error) {
+	return g.Commits.SetCommitStatus(fmt.Sprintf("%s/%s", g.namespace, g.project), sha, opt, options...)
+}
+
+// ListProjectIssues is a wrapper of IssuesService.ListProjectIssues
+func (g *GitLab) ListProjectIssues(opt *gitlab.ListProjectIssuesOptions, options ...gitlab.RequestOptionFunc) ([]*gitlab.Issue, *gitlab.Response, error) {
+	return g.Issues.ListProjectIssues(fmt.Sprintf("%s/%s", g.namespace, g.project), opt, options...)
+}
+
+// CreateIssue is a wrapper of IssuesService.CreateIssue
+func (g *GitLab) CreateIssue(opt *gitlab.CreateIssueOptions, options ...gitlab.RequestOptionFunc) (*gitlab.Issue, *gitlab.Response, error) {
+	return g.Issues.CreateIssue(fmt.Sprintf("%s/%s", g.namespace, g.project), opt, options...)
+}
+
+// UpdateIssue is a wrapper of IssuesService.UpdateIssue
+func (g *GitLab) UpdateIssue(issue int, opt *gitlab.UpdateIssueOptions, options ...gitlab.RequestOptionFunc) (*gitlab.Issue, *gitlab.Response, error) {
+	return g.Issues.UpdateIssue(fmt.Sprintf("%s/%s", g.namespace, g.project), int64(issue), opt, options...)
+}
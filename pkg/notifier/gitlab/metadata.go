@@ -0,0 +1,120 @@
+package gitlab
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hirosassa/tfcmt-gitlab/pkg/terraform"
+)
+
+const (
+	commandPlan    = "plan"
+	commandApply   = "apply"
+	commandPlanAll = "plan-all"
+	commandDrift   = "drift"
+)
+
+const (
+	metadataProgram = "tfcmt-gitlab"
+	metadataPrefix  = "<!-- " + metadataProgram + ": "
+	metadataSuffix  = " -->"
+)
+
+// Metadata is embedded in the posted body as a HTML comment to identify the comment later
+type Metadata struct {
+	Program    string            `json:"program"`
+	Command    string            `json:"command"`
+	Target     string            `json:"target,omitempty"`
+	SHA        string            `json:"sha,omitempty"`
+	PipelineID string            `json:"pipeline_id,omitempty"`
+	Vars       map[string]string `json:"vars,omitempty"`
+	// Part is the number of the part when the body is split into multiple notes
+	Part int `json:"part,omitempty"`
+	// Parent is the ID of the first note when the body is split into multiple notes
+	Parent int64 `json:"parent,omitempty"`
+	// Plan is the summary of the changes in the plan. It's compared with the apply result
+	Plan *terraform.ChangeSummary `json:"plan,omitempty"`
+}
+
+// newMetadata returns the metadata of the comment which is posted by the command
+func (g *NotifyService) newMetadata(command string) *Metadata {
+	cfg := g.client.Config
+	var vars map[string]string
+	for _, name := range cfg.EmbeddedVarNames {
+		v, ok := cfg.Vars[name]
+		if !ok {
+			continue
+		}
+		if vars == nil {
+			vars = make(map[string]string, len(cfg.EmbeddedVarNames))
+		}
+		vars[name] = v
+	}
+	return &Metadata{
+		Program:    metadataProgram,
+		Command:    command,
+		Target:     cfg.Vars["target"],
+		SHA:        cfg.MR.Revision,
+		PipelineID: cfg.PipelineID,
+		Vars:       vars,
+	}
+}
+
+// Embed appends the metadata to the body
+func (m *Metadata) Embed(body string) (string, error) {
+	b, err := json.Marshal(m)
+	if err != nil {
+		return "", fmt.Errorf("marshal the metadata as JSON: %w", err)
+	}
+	// json.Marshal escapes "<" and ">", so "-->" in the metadata never closes the HTML comment
+	return body + "\n" + metadataPrefix + string(b) + metadataSuffix + "\n", nil
+}
+
+// IsSameTarget returns true if the other metadata is of the same command and target.
+// The embedded variables are compared too.
+func (m *Metadata) IsSameTarget(other *Metadata) bool {
+	if m.Program != other.Program || m.Command != other.Command || m.Target != other.Target {
+		return false
+	}
+	if len(m.Vars) != len(other.Vars) {
+		return false
+	}
+	for k, v := range m.Vars {
+		if ov, ok := other.Vars[k]; !ok || ov != v {
+			return false
+		}
+	}
+	return true
+}
+
+// extractMetadata extracts the metadata from the body.
+// It returns nil if the body doesn't have the metadata.
+func extractMetadata(body string) *Metadata {
+	start := strings.LastIndex(body, metadataPrefix)
+	if start == -1 {
+		return nil
+	}
+	s := body[start+len(metadataPrefix):]
+	end := strings.Index(s, metadataSuffix)
+	if end == -1 {
+		return nil
+	}
+	m := &Metadata{}
+	if err := json.Unmarshal([]byte(s[:end]), m); err != nil {
+		return nil
+	}
+	return m
+}
+
+// isSameTarget returns true if the body was posted by the same command for the same target.
+// The comments posted before the metadata was introduced are compared by the title of the plan.
+func (g *NotifyService) isSameTarget(template *terraform.Template, command, body string) bool {
+	if isHiddenComment(body) {
+		return false
+	}
+	if m := extractMetadata(body); m != nil {
+		return g.newMetadata(command).IsSameTarget(m)
+	}
+	return command == commandPlan && template.IsSamePlan(body)
+}
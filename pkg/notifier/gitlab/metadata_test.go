@@ -0,0 +1,151 @@
+package gitlab
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hirosassa/tfcmt-gitlab/pkg/notifier"
+	gitlabmock "github.com/hirosassa/tfcmt-gitlab/pkg/notifier/gitlab/gen"
+	"github.com/hirosassa/tfcmt-gitlab/pkg/terraform"
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+	"go.uber.org/mock/gomock"
+)
+
+func TestMetadataEmbed(t *testing.T) {
+	t.Parallel()
+	m := &Metadata{
+		Program:    metadataProgram,
+		Command:    commandPlan,
+		Target:     "foo",
+		SHA:        "abcd",
+		PipelineID: "100",
+		Vars:       map[string]string{"env": "<!-- --> prod"},
+	}
+	body, err := m.Embed("body")
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := `body
+<!-- tfcmt-gitlab: {"program":"tfcmt-gitlab","command":"plan","target":"foo","sha":"abcd","pipeline_id":"100","vars":{"env":"\u003c!-- --\u003e prod"}} -->
+`
+	if body != exp {
+		t.Errorf("wanted %q, got %q", exp, body)
+	}
+	if diff := cmp.Diff(m, extractMetadata(body)); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestExtractMetadata(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		name string
+		body string
+		exp  *Metadata
+	}{
+		{
+			name: "no metadata",
+			body: "## Plan Result",
+		},
+		{
+			name: "broken metadata",
+			body: "## Plan Result\n<!-- tfcmt-gitlab: {\"program\": -->",
+		},
+		{
+			name: "unclosed metadata",
+			body: "## Plan Result\n<!-- tfcmt-gitlab: {}",
+		},
+		{
+			name: "the last metadata is used",
+			body: "<!-- tfcmt-gitlab: {\"command\":\"apply\"} -->\n<!-- tfcmt-gitlab: {\"command\":\"plan\"} -->",
+			exp:  &Metadata{Command: "plan"},
+		},
+	}
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+			if diff := cmp.Diff(testCase.exp, extractMetadata(testCase.body)); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestMetadataIsSameTarget(t *testing.T) {
+	t.Parallel()
+	base := Metadata{Program: metadataProgram, Command: commandPlan, Target: "foo", SHA: "abcd", Vars: map[string]string{"env": "prod"}}
+	testCases := []struct {
+		name  string
+		other Metadata
+		exp   bool
+	}{
+		{
+			name:  "the sha and the pipeline are ignored",
+			other: Metadata{Program: metadataProgram, Command: commandPlan, Target: "foo", SHA: "efgh", PipelineID: "1", Vars: map[string]string{"env": "prod"}},
+			exp:   true,
+		},
+		{
+			name:  "different target",
+			other: Metadata{Program: metadataProgram, Command: commandPlan, Target: "bar", Vars: map[string]string{"env": "prod"}},
+		},
+		{
+			name:  "different command",
+			other: Metadata{Program: metadataProgram, Command: commandApply, Target: "foo", Vars: map[string]string{"env": "prod"}},
+		},
+		{
+			name:  "different embedded variable",
+			other: Metadata{Program: metadataProgram, Command: commandPlan, Target: "foo", Vars: map[string]string{"env": "dev"}},
+		},
+		{
+			name:  "missing embedded variable",
+			other: Metadata{Program: metadataProgram, Command: commandPlan, Target: "foo"},
+		},
+	}
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+			if got := base.IsSameTarget(&testCase.other); got != testCase.exp {
+				t.Errorf("wanted %v, got %v", testCase.exp, got)
+			}
+		})
+	}
+}
+
+func TestNotifyNotifyPatchByMetadata(t *testing.T) {
+	t.Parallel()
+	const customTemplate = "# {{.Result}}"
+	cfg := newFakeConfig()
+	cfg.Patch = true
+	cfg.Template = terraform.NewPlanTemplate(customTemplate)
+	cfg.Vars = map[string]string{"env": "prod"}
+	cfg.EmbeddedVarNames = []string{"env"}
+	client, err := NewClient(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sameTarget, err := client.Notify.newMetadata(commandPlan).Embed("# old result")
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherEnv, err := (&Metadata{Program: metadataProgram, Command: commandPlan, Vars: map[string]string{"env": "dev"}}).Embed("# other env")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	api := gitlabmock.NewMockAPI(mockCtrl)
+	api.EXPECT().ListMergeRequestNotes(1, gomock.Any()).Return([]*gitlab.Note{
+		{ID: 10, Body: sameTarget},
+		{ID: 11, Body: otherEnv},
+	}, &gitlab.Response{}, nil)
+	api.EXPECT().UpdateMergeRequestNote(1, 10, gomock.Any()).Return(nil, nil, nil)
+	client.API = api
+
+	if _, err := client.Notify.Notify(notifier.ParamExec{CombinedOutput: "Plan: 1 to add, 0 to change, 0 to destroy."}); err != nil {
+		t.Fatal(err)
+	}
+}
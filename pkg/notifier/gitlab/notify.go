@@ -1,6 +1,10 @@
 package gitlab
 
 import (
+	"errors"
+	"fmt"
+	"slices"
+
 	"github.com/hirosassa/tfcmt-gitlab/pkg/notifier"
 	"github.com/hirosassa/tfcmt-gitlab/pkg/terraform"
 	"github.com/sirupsen/logrus"
@@ -18,8 +22,7 @@ func (g *NotifyService) Notify(param notifier.ParamExec) (int, error) { //nolint
 	template := g.client.Config.Template
 	var errMsgs []string
 
-	result := parser.Parse(param.CombinedOutput)
-	result.ExitCode = param.ExitCode
+	result := g.parse(param)
 	if result.HasParseError {
 		template = g.client.Config.ParseErrorTemplate
 	} else {
@@ -31,20 +34,133 @@ func (g *NotifyService) Notify(param notifier.ParamExec) (int, error) { //nolint
 		}
 	}
 
-	_, isPlan := parser.(*terraform.PlanParser)
-	if isPlan {
+	if notifier.IsPlanParser(parser) {
 		if cfg.MR.IsNumber() && cfg.ResultLabels.HasAnyLabelDefined() {
 			errMsgs = append(errMsgs, g.updateLabels(result)...)
 		}
+		if cfg.MR.IsNumber() && cfg.DestroyApprovalRule.Name != "" {
+			if err := g.updateDestroyApprovalRule(result); err != nil {
+				logrus.WithFields(logrus.Fields{
+					"program": "tfcmt",
+				}).WithError(err).Error("update the approval rule")
+				errMsgs = append(errMsgs, "update the approval rule: "+err.Error())
+			}
+		}
+		if err := g.writeTerraformReport(result); err != nil {
+			logrus.WithFields(logrus.Fields{
+				"program": "tfcmt",
+			}).WithError(err).Error("write the terraform report")
+			errMsgs = append(errMsgs, "write the terraform report: "+err.Error())
+		}
+	}
+
+	command := commandPlan
+	if _, isApply := parser.(*terraform.ApplyParser); isApply {
+		command = commandApply
+	}
+
+	if cfg.CommitStatus.Name != "" {
+		if err := g.setCommitStatus(command, cfg.Vars["target"], result); err != nil {
+			logrus.WithFields(logrus.Fields{
+				"program": "tfcmt",
+			}).WithError(err).Error("set the commit status")
+			errMsgs = append(errMsgs, "set the commit status: "+err.Error())
+		}
+	}
+
+	fullOutputURL, err := g.uploadFullOutput(command, param.CombinedOutput)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"program": "tfcmt",
+		}).WithError(err).Error("upload the full output")
+		errMsgs = append(errMsgs, "upload the full output: "+err.Error())
+	}
+
+	ct := g.commonTemplate(param, result, errMsgs, fullOutputURL)
+	var plan *planComment
+	if command == commandApply && cfg.LinkPlan {
+		logE := logrus.WithFields(logrus.Fields{
+			"program": "tfcmt",
+		})
+		if err := g.resolveMergeRequest(); err != nil {
+			logE.WithError(err).Warn("find the merge request")
+		}
+		cfg = g.client.Config
+		p, err := g.findPlanComment()
+		if err != nil {
+			logE.WithError(err).Warn("find the plan comment")
+		}
+		plan = p
+	}
+	if plan != nil {
+		linkPlan(&ct, plan, result)
 	}
 
-	template.SetValue(terraform.CommonTemplate{
+	template.SetValue(ct)
+	body, err := template.Execute()
+	if err != nil {
+		return result.ExitCode, err
+	}
+
+	meta := g.newMetadata(command)
+	if command == commandPlan && !result.HasParseError && !result.HasPlanError {
+		summary := terraform.PlannedChanges(result)
+		meta.Plan = &summary
+	}
+	skip := result.HasNoChanges && result.Warning == "" && len(errMsgs) == 0 && cfg.SkipNoChanges
+	exitCode := notifier.PolicyExitCode(result.ExitCode, result)
+
+	if command != commandApply && cfg.Discussion && cfg.MR.IsNumber() {
+		body, err = meta.Embed(body)
+		if err != nil {
+			return exitCode, err
+		}
+		return exitCode, g.notifyDiscussion(template, body, result, skip)
+	}
+
+	if plan != nil && plan.discussion != "" {
+		// the apply result is replied to the thread of the plan in the discussion mode
+		body, err = meta.Embed(body)
+		if err != nil {
+			return exitCode, err
+		}
+		return exitCode, g.client.Discussion.Reply(plan.discussion, body, cfg.MR.Number)
+	}
+
+	return exitCode, g.postComment(template, meta, body, skip)
+}
+
+// Render renders the comment without posting it to GitLab.
+// Labels, the full output and the report for the Terraform widget aren't handled.
+func (g *NotifyService) Render(param notifier.ParamExec) (string, error) {
+	template := g.client.Config.Template
+
+	result := g.parse(param)
+	if result.HasParseError {
+		template = g.client.Config.ParseErrorTemplate
+	} else {
+		if result.Error != nil {
+			return "", result.Error
+		}
+		if result.Result == "" {
+			return "", errors.New("the result isn't found in the output")
+		}
+	}
+
+	template.SetValue(g.commonTemplate(param, result, nil, ""))
+	return template.Execute()
+}
+
+func (g *NotifyService) commonTemplate(param notifier.ParamExec, result terraform.ParseResult, errMsgs []string, fullOutputURL string) terraform.CommonTemplate {
+	cfg := g.client.Config
+	return terraform.CommonTemplate{
 		Result:                 result.Result,
 		ChangedResult:          result.ChangedResult,
 		ChangeOutsideTerraform: result.OutsideTerraform,
 		Warning:                result.Warning,
 		Link:                   cfg.CI,
 		UseRawOutput:           cfg.UseRawOutput,
+		HasChanges:             result.HasChanges,
 		HasDestroy:             result.HasDestroy,
 		Vars:                   cfg.Vars,
 		Templates:              cfg.Templates,
@@ -57,74 +173,237 @@ func (g *NotifyService) Notify(param notifier.ParamExec) (int, error) { //nolint
 		UpdatedResources:       result.UpdatedResources,
 		DeletedResources:       result.DeletedResources,
 		ReplacedResources:      result.ReplacedResources,
-	})
-	body, err := template.Execute()
-	if err != nil {
-		return result.ExitCode, err
+		MovedResources:         result.MovedResources,
+		ImportedResources:      result.ImportedResources,
+		ForgottenResources:     result.ForgottenResources,
+		ChangedOutputs:         result.ChangedOutputs,
+		FullOutputURL:          fullOutputURL,
+		PolicyViolations:       result.PolicyViolations,
+		Tool:                   terraform.ResolveToolName(cfg.Tool, result),
+		Modules:                result.Modules,
+		DriftedResources:       result.DriftedResources,
+		AppliedResources:       result.AppliedResources,
 	}
+}
 
-	_, isApply := parser.(*terraform.ApplyParser)
+// postComment posts the body with the metadata as a new comment.
+// If patching is enabled, the comment of the same target is updated instead.
+// If the body is too long and splitting comments is enabled, the body is posted as multiple notes.
+func (g *NotifyService) postComment(template *terraform.Template, meta *Metadata, body string, skip bool) error {
+	cfg := g.client.Config
+	command := meta.Command
+	parts := g.split(body)
 	logE := logrus.WithFields(logrus.Fields{
 		"program": "tfcmt",
 	})
 
-	if !isApply && cfg.Patch && cfg.MR.Number != 0 {
+	if command != commandApply && cfg.Patch && cfg.MR.Number != 0 {
 		logE.Debug("try patching")
 		// If fail to list comments, try to create new post.
 		comments, _ := g.client.Comment.List(cfg.MR.Number)
-		for i := len(comments) - 1; i >= 0; i-- {
-			comment := comments[i]
-
-			if template.IsSamePlan(comment.Body) {
-				logE.Debugf("Patch comment from `%s` to `%s`", comment.Body, body)
-				if err := g.client.Comment.Patch(int(comment.ID), body, PostOptions{
-					Number:   cfg.MR.Number,
-					Revision: cfg.MR.Revision,
-				}); err != nil {
-					return result.ExitCode, err
-				}
-				return result.ExitCode, nil
-			}
+		if series := g.findSeries(template, command, comments); len(series) != 0 {
+			logE.WithField("parts", len(parts)).Debugf("Patch comment from `%s` to `%s`", series[0].Body, body)
+			return g.postSeries(meta, parts, series)
 		}
 		logE.WithField("size", len(comments)).Debug("list comments")
 	}
 
-	if result.HasNoChanges && result.Warning == "" && len(errMsgs) == 0 && cfg.SkipNoChanges {
+	if skip {
 		logE.Debug("skip posting a comment because there is no change")
-		return result.ExitCode, nil
+		return nil
+	}
+
+	var outdatedComments []*gitlab.Note
+	if command != commandApply && cfg.OutdatedComment != "" && cfg.MR.IsNumber() {
+		// list the comments before posting a new comment not to handle the new comment as outdated
+		comments, err := g.client.Comment.List(cfg.MR.Number)
+		if err != nil {
+			logE.WithError(err).Warn("list comments")
+		}
+		for _, comment := range comments {
+			if g.isSameTarget(template, command, comment.Body) {
+				outdatedComments = append(outdatedComments, comment)
+			}
+		}
 	}
 
 	logE.Debug("create a comment")
 
+	if len(parts) > 1 {
+		if err := g.postSeries(meta, parts, nil); err != nil {
+			return err
+		}
+		return g.handleOutdatedComments(outdatedComments)
+	}
+
+	body, err := meta.Embed(body)
+	if err != nil {
+		return err
+	}
 	if err := g.client.Comment.Post(body, PostOptions{
 		Number:   cfg.MR.Number,
 		Revision: cfg.MR.Revision,
 	}); err != nil {
-		return result.ExitCode, err
+		return err
 	}
-	return result.ExitCode, nil
+
+	return g.handleOutdatedComments(outdatedComments)
+}
+
+// handleOutdatedComments hides or deletes the older comments of the same target
+func (g *NotifyService) handleOutdatedComments(comments []*gitlab.Note) error {
+	cfg := g.client.Config
+	opt := PostOptions{
+		Number:   cfg.MR.Number,
+		Revision: cfg.MR.Revision,
+	}
+	for _, comment := range comments {
+		switch cfg.OutdatedComment {
+		case OutdatedCommentHide:
+			if err := g.client.Comment.Hide(comment, opt); err != nil {
+				return fmt.Errorf("hide an outdated comment (%d): %w", comment.ID, err)
+			}
+		case OutdatedCommentDelete:
+			if err := g.client.Comment.Delete(int(comment.ID), opt); err != nil {
+				return fmt.Errorf("delete an outdated comment (%d): %w", comment.ID, err)
+			}
+		}
+	}
+	return nil
+}
+
+// notifyDiscussion posts the plan result as a resolvable discussion.
+// If patching is enabled, the discussion of the same target is updated instead.
+// If AutoResolveDiscussion is enabled, the discussions of the same target are resolved when the plan has no destroy and no error,
+// and the updated discussion is unresolved again when the plan has destroy or fails.
+func (g *NotifyService) notifyDiscussion(template *terraform.Template, body string, result terraform.ParseResult, skip bool) error {
+	cfg := g.client.Config
+	number := cfg.MR.Number
+	resolve := cfg.AutoResolveDiscussion && !result.HasDestroy && !result.HasPlanError && !result.HasParseError
+
+	logE := logrus.WithFields(logrus.Fields{
+		"program": "tfcmt",
+	})
+
+	// If fail to list discussions, try to create new discussion.
+	discussions, err := g.client.Discussion.List(number)
+	if err != nil {
+		logE.WithError(err).Warn("list discussions")
+	}
+	var sameTargetDiscussions []*gitlab.Discussion
+	for _, discussion := range discussions {
+		if g.isSameTarget(template, commandPlan, discussion.Notes[0].Body) {
+			sameTargetDiscussions = append(sameTargetDiscussions, discussion)
+		}
+	}
+
+	if cfg.Patch && len(sameTargetDiscussions) != 0 {
+		discussion := sameTargetDiscussions[len(sameTargetDiscussions)-1]
+		note := discussion.Notes[0]
+		logE.WithField("discussion", discussion.ID).Debug("patch the discussion")
+		if err := g.client.Discussion.Patch(discussion.ID, int(note.ID), body, number); err != nil {
+			return err
+		}
+		if cfg.AutoResolveDiscussion && note.Resolved != resolve {
+			if err := g.client.Discussion.Resolve(discussion.ID, resolve, number); err != nil {
+				return err
+			}
+		}
+		sameTargetDiscussions = sameTargetDiscussions[:len(sameTargetDiscussions)-1]
+	} else {
+		if skip {
+			logE.Debug("skip posting a discussion because there is no change")
+		} else {
+			logE.Debug("create a discussion")
+			discussion, err := g.client.Discussion.Post(body, number)
+			if err != nil {
+				return err
+			}
+			if resolve {
+				if err := g.client.Discussion.Resolve(discussion.ID, true, number); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	if !resolve {
+		return nil
+	}
+	// the previous plans of the same target are outdated
+	for _, discussion := range sameTargetDiscussions {
+		if discussion.Notes[0].Resolved {
+			continue
+		}
+		if err := g.client.Discussion.Resolve(discussion.ID, true, number); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resultLabel is a label which is added to the merge request depending on the plan result
+type resultLabel struct {
+	name  string
+	color string
 }
 
-func (g *NotifyService) updateLabels(result terraform.ParseResult) []string { //nolint:cyclop
+// labelsToAdd returns the labels which should be added to the merge request.
+// The first one represents the overall result and the others represent the additional operations like moves.
+func (g *NotifyService) labelsToAdd(result terraform.ParseResult) []resultLabel {
 	cfg := g.client.Config
-	var (
-		labelToAdd string
-		labelColor string
-	)
+	labels := []resultLabel{}
 
+	// the plan error is checked first because the aggregated result of plan-all can have both the error and the destroy
 	switch {
+	case result.HasPlanError:
+		labels = append(labels, resultLabel{name: cfg.ResultLabels.PlanErrorLabel, color: cfg.ResultLabels.PlanErrorLabelColor})
 	case result.HasAddOrUpdateOnly:
-		labelToAdd = cfg.ResultLabels.AddOrUpdateLabel
-		labelColor = cfg.ResultLabels.AddOrUpdateLabelColor
+		labels = append(labels, resultLabel{name: cfg.ResultLabels.AddOrUpdateLabel, color: cfg.ResultLabels.AddOrUpdateLabelColor})
 	case result.HasDestroy:
-		labelToAdd = cfg.ResultLabels.DestroyLabel
-		labelColor = cfg.ResultLabels.DestroyLabelColor
+		labels = append(labels, resultLabel{name: cfg.ResultLabels.DestroyLabel, color: cfg.ResultLabels.DestroyLabelColor})
 	case result.HasNoChanges:
-		labelToAdd = cfg.ResultLabels.NoChangesLabel
-		labelColor = cfg.ResultLabels.NoChangesLabelColor
-	case result.HasPlanError:
-		labelToAdd = cfg.ResultLabels.PlanErrorLabel
-		labelColor = cfg.ResultLabels.PlanErrorLabelColor
+		labels = append(labels, resultLabel{name: cfg.ResultLabels.NoChangesLabel, color: cfg.ResultLabels.NoChangesLabelColor})
+	}
+
+	if len(result.MovedResources) != 0 {
+		labels = append(labels, resultLabel{name: cfg.ResultLabels.MovedLabel, color: cfg.ResultLabels.MovedLabelColor})
+	}
+	if len(result.ImportedResources) != 0 {
+		labels = append(labels, resultLabel{name: cfg.ResultLabels.ImportedLabel, color: cfg.ResultLabels.ImportedLabelColor})
+	}
+	if len(result.ForgottenResources) != 0 {
+		labels = append(labels, resultLabel{name: cfg.ResultLabels.ForgottenLabel, color: cfg.ResultLabels.ForgottenLabelColor})
+	}
+	if len(result.PolicyViolations) != 0 {
+		labels = append(labels, resultLabel{name: cfg.ResultLabels.PolicyViolationLabel, color: cfg.ResultLabels.PolicyViolationLabelColor})
+	}
+
+	ret := make([]resultLabel, 0, len(labels))
+	for _, label := range labels {
+		if label.name != "" {
+			ret = append(ret, label)
+		}
+	}
+	return ret
+}
+
+// parse parses the output of the command and maps the exit code of the command into the result
+func (g *NotifyService) parse(param notifier.ParamExec) terraform.ParseResult {
+	cfg := g.client.Config
+	return notifier.Parse(param, notifier.ParseOption{
+		Parser:           cfg.Parser,
+		DetailedExitCode: cfg.DetailedExitCode,
+		ChangesExitCode:  cfg.ChangesExitCode,
+		Policy:           cfg.Policy,
+	})
+}
+
+func (g *NotifyService) updateLabels(result terraform.ParseResult) []string {
+	labelsToAdd := g.labelsToAdd(result)
+	labelNames := make([]string, len(labelsToAdd))
+	for i, label := range labelsToAdd {
+		labelNames[i] = label.name
 	}
 
 	errMsgs := []string{}
@@ -133,16 +412,28 @@ func (g *NotifyService) updateLabels(result terraform.ParseResult) []string { //
 		"program": "tfcmt",
 	})
 
-	currentLabelColor, err := g.removeResultLabels(labelToAdd)
+	currentLabelColors, err := g.removeResultLabels(labelNames)
 	if err != nil {
 		msg := "remove labels: " + err.Error()
 		logE.WithError(err).Error("remove labels")
 		errMsgs = append(errMsgs, msg)
 	}
 
-	if labelToAdd == "" {
-		return errMsgs
+	for _, label := range labelsToAdd {
+		errMsgs = append(errMsgs, g.addLabel(label.name, label.color, currentLabelColors[label.name])...)
 	}
+	return errMsgs
+}
+
+// addLabel adds a label to the merge request and updates the color of the label.
+// currentLabelColor is the color of the label if the label has already been added to the merge request.
+func (g *NotifyService) addLabel(labelToAdd, labelColor, currentLabelColor string) []string { //nolint:cyclop
+	cfg := g.client.Config
+	errMsgs := []string{}
+
+	logE := logrus.WithFields(logrus.Fields{
+		"program": "tfcmt",
+	})
 
 	if currentLabelColor == "" {
 		labels, err := g.client.API.AddMergeRequestLabels(&[]string{labelToAdd}, cfg.MR.Number)
@@ -164,6 +455,7 @@ func (g *NotifyService) updateLabels(result terraform.ParseResult) []string { //
 							"label": labelToAdd,
 						}).Error("get a label")
 						errMsgs = append(errMsgs, msg)
+						continue
 					}
 
 					if l.Color != labelColor {
@@ -193,31 +485,33 @@ func (g *NotifyService) updateLabels(result terraform.ParseResult) []string { //
 	return errMsgs
 }
 
-func (g *NotifyService) removeResultLabels(label string) (string, error) {
+// removeResultLabels removes the result labels except for labelsToKeep from the merge request.
+// It returns the current colors of labelsToKeep which have already been added to the merge request.
+func (g *NotifyService) removeResultLabels(labelsToKeep []string) (map[string]string, error) {
 	cfg := g.client.Config
+	labelColors := map[string]string{}
 	labels, err := g.client.API.ListMergeRequestLabels(cfg.MR.Number, nil)
 	if err != nil {
-		return "", err
+		return labelColors, err
 	}
 
-	labelColor := ""
 	for _, l := range labels {
 		labelText := l
-		if labelText == label {
+		if slices.Contains(labelsToKeep, labelText) {
 			currentLabel, _, err := g.client.API.GetLabel(l)
 			if err != nil {
-				return "", err
+				return labelColors, err
 			}
-			labelColor = currentLabel.Color
+			labelColors[labelText] = currentLabel.Color
 			continue
 		}
 		if cfg.ResultLabels.IsResultLabel(labelText) {
 			_, err := g.client.API.RemoveMergeRequestLabels(&[]string{labelText}, cfg.MR.Number)
 			if err != nil {
-				return labelColor, err
+				return labelColors, err
 			}
 		}
 	}
 
-	return labelColor, nil
+	return labelColors, nil
 }
@@ -0,0 +1,127 @@
+package gitlab
+
+import (
+	"strings"
+
+	"github.com/hirosassa/tfcmt-gitlab/pkg/notifier"
+	"github.com/hirosassa/tfcmt-gitlab/pkg/terraform"
+	"github.com/sirupsen/logrus"
+)
+
+// NotifyAll posts one comment which aggregates the results of multiple targets
+func (g *NotifyService) NotifyAll(params []notifier.ParamExec) (int, error) {
+	cfg := g.client.Config
+	template := cfg.Template
+	var errMsgs []string
+
+	targets := make([]terraform.TargetResult, len(params))
+	results := make([]terraform.ParseResult, len(params))
+	exitCode := terraform.ExitPass
+	for i, param := range params {
+		result := g.parse(param)
+		if result.ExitCode > exitCode {
+			exitCode = result.ExitCode
+		}
+		targets[i] = terraform.TargetResult{
+			ParseResult:    result,
+			Target:         param.Target,
+			CombinedOutput: param.CombinedOutput,
+		}
+		results[i] = result
+	}
+	result := aggregateResults(results)
+
+	if cfg.CommitStatus.Name != "" {
+		for _, target := range targets {
+			if err := g.setCommitStatus(commandPlan, target.Target, target.ParseResult); err != nil {
+				logrus.WithFields(logrus.Fields{
+					"program": "tfcmt",
+					"target":  target.Target,
+				}).WithError(err).Error("set the commit status")
+				errMsgs = append(errMsgs, "set the commit status: "+err.Error())
+			}
+		}
+	}
+
+	if cfg.MR.IsNumber() && cfg.ResultLabels.HasAnyLabelDefined() {
+		errMsgs = append(errMsgs, g.updateLabels(result)...)
+	}
+	if cfg.MR.IsNumber() && cfg.DestroyApprovalRule.Name != "" {
+		if err := g.updateDestroyApprovalRule(result); err != nil {
+			logrus.WithFields(logrus.Fields{
+				"program": "tfcmt",
+			}).WithError(err).Error("update the approval rule")
+			errMsgs = append(errMsgs, "update the approval rule: "+err.Error())
+		}
+	}
+	if err := g.writeTerraformReport(results...); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"program": "tfcmt",
+		}).WithError(err).Error("write the terraform report")
+		errMsgs = append(errMsgs, "write the terraform report: "+err.Error())
+	}
+
+	var fullOutput strings.Builder
+	for _, target := range targets {
+		fullOutput.WriteString("==> " + target.Target + "\n" + target.CombinedOutput + "\n")
+	}
+	fullOutputURL, err := g.uploadFullOutput(commandPlanAll, fullOutput.String())
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"program": "tfcmt",
+		}).WithError(err).Error("upload the full output")
+		errMsgs = append(errMsgs, "upload the full output: "+err.Error())
+	}
+
+	// The title shows the failure only if any target fails.
+	// The exit code can be ExitFail even if the plans succeed because ChangesExitCode is configurable
+	templateExitCode := terraform.ExitPass
+	if result.HasPlanError {
+		templateExitCode = terraform.ExitFail
+	}
+	template.SetValue(terraform.CommonTemplate{
+		Link:          cfg.CI,
+		UseRawOutput:  cfg.UseRawOutput,
+		HasChanges:    result.HasChanges,
+		HasDestroy:    result.HasDestroy,
+		Vars:          cfg.Vars,
+		Templates:     cfg.Templates,
+		ExitCode:      templateExitCode,
+		ErrorMessages: errMsgs,
+		Targets:       targets,
+		FullOutputURL: fullOutputURL,
+		Tool:          terraform.ResolveToolName(cfg.Tool, result),
+	})
+	body, err := template.Execute()
+	if err != nil {
+		return exitCode, err
+	}
+
+	skip := result.HasNoChanges && result.Warning == "" && len(errMsgs) == 0 && cfg.SkipNoChanges
+	return notifier.PolicyExitCode(exitCode, result), g.postComment(template, g.newMetadata(commandPlanAll), body, skip)
+}
+
+// aggregateResults combines the results of multiple targets into one result to decide the labels
+func aggregateResults(results []terraform.ParseResult) terraform.ParseResult {
+	combined := terraform.ParseResult{
+		HasNoChanges: len(results) != 0,
+	}
+	for _, result := range results {
+		combined.HasChanges = combined.HasChanges || result.HasChanges
+		combined.HasDestroy = combined.HasDestroy || result.HasDestroy
+		combined.HasPlanError = combined.HasPlanError || result.HasPlanError || result.HasParseError
+		combined.HasNoChanges = combined.HasNoChanges && result.HasNoChanges
+		if result.Warning != "" {
+			combined.Warning = result.Warning
+		}
+		combined.MovedResources = append(combined.MovedResources, result.MovedResources...)
+		combined.ImportedResources = append(combined.ImportedResources, result.ImportedResources...)
+		combined.ForgottenResources = append(combined.ForgottenResources, result.ForgottenResources...)
+		combined.PolicyViolations = append(combined.PolicyViolations, result.PolicyViolations...)
+		if result.Tool != "" {
+			combined.Tool = result.Tool
+		}
+	}
+	combined.HasAddOrUpdateOnly = !combined.HasNoChanges && !combined.HasDestroy && !combined.HasPlanError
+	return combined
+}
@@ -0,0 +1,185 @@
+package gitlab
+
+import (
+	"testing"
+
+	"github.com/hirosassa/tfcmt-gitlab/pkg/notifier"
+	gitlabmock "github.com/hirosassa/tfcmt-gitlab/pkg/notifier/gitlab/gen"
+	"github.com/hirosassa/tfcmt-gitlab/pkg/terraform"
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+	"go.uber.org/mock/gomock"
+)
+
+func TestNotifyNotifyAll(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		name                string
+		createMockGitLabAPI func(ctrl *gomock.Controller) *gitlabmock.MockAPI
+		config              Config
+		ok                  bool
+		exitCode            int
+		params              []notifier.ParamExec
+	}{
+		{
+			name: "post one comment and add the destroy label",
+			createMockGitLabAPI: func(ctrl *gomock.Controller) *gitlabmock.MockAPI {
+				api := gitlabmock.NewMockAPI(ctrl)
+				api.EXPECT().ListMergeRequestLabels(1, nil).Return(gitlab.Labels{}, nil)
+				api.EXPECT().AddMergeRequestLabels(&[]string{"destroy"}, 1).Return(gitlab.Labels{"destroy"}, nil)
+				api.EXPECT().CreateMergeRequestNote(1, gomock.Any()).Return(nil, nil, nil).Times(1)
+				return api
+			},
+			config: Config{
+				Token:     "token",
+				NameSpace: "namespace",
+				Project:   "project",
+				MR: MergeRequest{
+					Number: 1,
+				},
+				Parser:   terraform.NewPlanParser(),
+				Template: terraform.NewPlanAllTemplate(""),
+				ResultLabels: ResultLabels{
+					AddOrUpdateLabel: "add-or-update",
+					DestroyLabel:     "destroy",
+					NoChangesLabel:   "no-changes",
+				},
+			},
+			params: []notifier.ParamExec{
+				{
+					Target:         "dev",
+					CombinedOutput: "Plan: 1 to add, 0 to change, 0 to destroy.",
+				},
+				{
+					Target:         "prod",
+					CombinedOutput: "Plan: 0 to add, 0 to change, 1 to destroy.",
+				},
+			},
+			ok:       true,
+			exitCode: 0,
+		},
+		{
+			name: "return the largest exit code",
+			createMockGitLabAPI: func(ctrl *gomock.Controller) *gitlabmock.MockAPI {
+				api := gitlabmock.NewMockAPI(ctrl)
+				api.EXPECT().CreateMergeRequestNote(1, gomock.Any()).Return(nil, nil, nil).Times(1)
+				return api
+			},
+			config: Config{
+				Token:     "token",
+				NameSpace: "namespace",
+				Project:   "project",
+				MR: MergeRequest{
+					Number: 1,
+				},
+				Parser:   terraform.NewPlanParser(),
+				Template: terraform.NewPlanAllTemplate(""),
+			},
+			params: []notifier.ParamExec{
+				{
+					Target:         "dev",
+					CombinedOutput: "No changes. Your infrastructure matches the configuration.",
+				},
+				{
+					Target:         "prod",
+					CombinedOutput: "Error: Invalid reference",
+					ExitCode:       1,
+				},
+			},
+			ok:       true,
+			exitCode: 1,
+		},
+		{
+			name: "add the plan error label when a target fails and another destroys",
+			createMockGitLabAPI: func(ctrl *gomock.Controller) *gitlabmock.MockAPI {
+				api := gitlabmock.NewMockAPI(ctrl)
+				api.EXPECT().ListMergeRequestLabels(1, nil).Return(gitlab.Labels{}, nil)
+				api.EXPECT().AddMergeRequestLabels(&[]string{"plan-error"}, 1).Return(gitlab.Labels{"plan-error"}, nil)
+				api.EXPECT().CreateMergeRequestNote(1, gomock.Any()).Return(nil, nil, nil).Times(1)
+				return api
+			},
+			config: Config{
+				Token:     "token",
+				NameSpace: "namespace",
+				Project:   "project",
+				MR: MergeRequest{
+					Number: 1,
+				},
+				Parser:   terraform.NewPlanParser(),
+				Template: terraform.NewPlanAllTemplate(""),
+				ResultLabels: ResultLabels{
+					AddOrUpdateLabel: "add-or-update",
+					DestroyLabel:     "destroy",
+					NoChangesLabel:   "no-changes",
+					PlanErrorLabel:   "plan-error",
+				},
+			},
+			params: []notifier.ParamExec{
+				{
+					Target:         "dev",
+					CombinedOutput: "Error: Invalid reference",
+					ExitCode:       1,
+				},
+				{
+					Target:         "prod",
+					CombinedOutput: "Plan: 0 to add, 0 to change, 1 to destroy.",
+				},
+			},
+			ok:       true,
+			exitCode: 1,
+		},
+		{
+			name: "skip posting a comment when no target has changes",
+			createMockGitLabAPI: func(ctrl *gomock.Controller) *gitlabmock.MockAPI {
+				api := gitlabmock.NewMockAPI(ctrl)
+				api.EXPECT().CreateMergeRequestNote(gomock.Any(), gomock.Any()).Times(0)
+				return api
+			},
+			config: Config{
+				Token:     "token",
+				NameSpace: "namespace",
+				Project:   "project",
+				MR: MergeRequest{
+					Number: 1,
+				},
+				Parser:        terraform.NewPlanParser(),
+				Template:      terraform.NewPlanAllTemplate(""),
+				SkipNoChanges: true,
+			},
+			params: []notifier.ParamExec{
+				{
+					Target:         "dev",
+					CombinedOutput: "No changes. Your infrastructure matches the configuration.",
+				},
+				{
+					Target:         "prod",
+					CombinedOutput: "No changes. Your infrastructure matches the configuration.",
+				},
+			},
+			ok:       true,
+			exitCode: 0,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+			client, err := NewClient(testCase.config)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			client.API = testCase.createMockGitLabAPI(mockCtrl)
+
+			exitCode, err := client.Notify.NotifyAll(testCase.params)
+			if (err == nil) != testCase.ok {
+				t.Errorf("test case: %s, got error %q", testCase.name, err)
+			}
+			if exitCode != testCase.exitCode {
+				t.Errorf("test case: %s, got %q but want %q", testCase.name, exitCode, testCase.exitCode)
+			}
+		})
+	}
+}
@@ -1,6 +1,8 @@
 package gitlab
 
 import (
+	"bytes"
+	"strings"
 	"testing"
 
 	"github.com/hirosassa/tfcmt-gitlab/pkg/notifier"
@@ -260,6 +262,88 @@ func TestNotifyNotify(t *testing.T) { //nolint:maintidx
 			ok:       true,
 			exitCode: 2,
 		},
+		{
+			name: "valid, parse the plan JSON",
+			createMockGitLabAPI: func(ctrl *gomock.Controller) *gitlabmock.MockAPI {
+				api := gitlabmock.NewMockAPI(ctrl)
+				api.EXPECT().CreateMergeRequestNote(1, gomock.Any()).Return(nil, nil, nil)
+				return api
+			},
+			config: Config{
+				Token:     "token",
+				NameSpace: "namespace",
+				Project:   "project",
+				MR: MergeRequest{
+					Revision: "",
+					Number:   1,
+				},
+				Parser:             terraform.NewJSONPlanParser(),
+				Template:           terraform.NewPlanTemplate(terraform.DefaultPlanTemplate),
+				ParseErrorTemplate: terraform.NewPlanParseErrorTemplate(terraform.DefaultPlanTemplate),
+			},
+			paramExec: notifier.ParamExec{
+				CombinedOutput: "Saved the plan to: tfplan",
+				PlanJSON:       `{"format_version": "1.2", "resource_changes": [{"address": "null_resource.foo", "mode": "managed", "change": {"actions": ["create"]}}]}`,
+				ExitCode:       0,
+			},
+			ok:       true,
+			exitCode: 0,
+		},
+		{
+			name: "detailed exit code, the plan has changes",
+			createMockGitLabAPI: func(ctrl *gomock.Controller) *gitlabmock.MockAPI {
+				api := gitlabmock.NewMockAPI(ctrl)
+				api.EXPECT().CreateMergeRequestNote(1, gomock.Any()).Return(nil, nil, nil)
+				return api
+			},
+			config: Config{
+				Token:     "token",
+				NameSpace: "namespace",
+				Project:   "project",
+				MR: MergeRequest{
+					Revision: "",
+					Number:   1,
+				},
+				Parser:             terraform.NewPlanParser(),
+				Template:           terraform.NewPlanTemplate(terraform.DefaultPlanTemplate),
+				ParseErrorTemplate: terraform.NewPlanParseErrorTemplate(terraform.DefaultPlanTemplate),
+				DetailedExitCode:   true,
+			},
+			paramExec: notifier.ParamExec{
+				CombinedOutput: "Plan: 1 to add, 0 to change, 0 to destroy.",
+				ExitCode:       2,
+			},
+			ok:       true,
+			exitCode: 0,
+		},
+		{
+			name: "detailed exit code, return the configured exit code when the plan has changes",
+			createMockGitLabAPI: func(ctrl *gomock.Controller) *gitlabmock.MockAPI {
+				api := gitlabmock.NewMockAPI(ctrl)
+				api.EXPECT().CreateMergeRequestNote(1, gomock.Any()).Return(nil, nil, nil)
+				return api
+			},
+			config: Config{
+				Token:     "token",
+				NameSpace: "namespace",
+				Project:   "project",
+				MR: MergeRequest{
+					Revision: "",
+					Number:   1,
+				},
+				Parser:             terraform.NewPlanParser(),
+				Template:           terraform.NewPlanTemplate(terraform.DefaultPlanTemplate),
+				ParseErrorTemplate: terraform.NewPlanParseErrorTemplate(terraform.DefaultPlanTemplate),
+				DetailedExitCode:   true,
+				ChangesExitCode:    2,
+			},
+			paramExec: notifier.ParamExec{
+				CombinedOutput: "Plan: 1 to add, 0 to change, 0 to destroy.",
+				ExitCode:       2,
+			},
+			ok:       true,
+			exitCode: 2,
+		},
 		{
 			name: "get MR IID when MR number is 0",
 			createMockGitLabAPI: func(ctrl *gomock.Controller) *gitlabmock.MockAPI {
@@ -313,3 +397,383 @@ func TestNotifyNotify(t *testing.T) { //nolint:maintidx
 		})
 	}
 }
+
+func TestNotifyUpdateLabels(t *testing.T) {
+	t.Parallel()
+	resultLabels := ResultLabels{
+		AddOrUpdateLabel: "add-or-update",
+		DestroyLabel:     "destroy",
+		NoChangesLabel:   "no-changes",
+		MovedLabel:       "moved",
+		MovedLabelColor:  "#aaaaaa",
+		ImportedLabel:    "imported",
+	}
+	testCases := []struct {
+		name                string
+		createMockGitLabAPI func(ctrl *gomock.Controller) *gitlabmock.MockAPI
+		result              terraform.ParseResult
+	}{
+		{
+			name: "add the result label and the moved label",
+			createMockGitLabAPI: func(ctrl *gomock.Controller) *gitlabmock.MockAPI {
+				api := gitlabmock.NewMockAPI(ctrl)
+				api.EXPECT().ListMergeRequestLabels(1, nil).Return(gitlab.Labels{"no-changes", "imported", "other"}, nil)
+				api.EXPECT().RemoveMergeRequestLabels(&[]string{"no-changes"}, 1).Return(nil, nil)
+				api.EXPECT().RemoveMergeRequestLabels(&[]string{"imported"}, 1).Return(nil, nil)
+				api.EXPECT().AddMergeRequestLabels(&[]string{"add-or-update"}, 1).Return(gitlab.Labels{"add-or-update"}, nil)
+				api.EXPECT().AddMergeRequestLabels(&[]string{"moved"}, 1).Return(gitlab.Labels{"add-or-update", "moved"}, nil)
+				api.EXPECT().GetLabel("moved").Return(&gitlab.Label{Name: "moved", Color: "#000000"}, nil, nil)
+				api.EXPECT().UpdateLabel(&gitlab.UpdateLabelOptions{Name: gitlab.Ptr("moved"), Color: gitlab.Ptr("#aaaaaa")}).Return(nil, nil, nil)
+				return api
+			},
+			result: terraform.ParseResult{
+				HasAddOrUpdateOnly: true,
+				MovedResources:     []terraform.MovedResource{{From: "a.b", To: "a.c"}},
+			},
+		},
+		{
+			name: "keep the labels which have already been added",
+			createMockGitLabAPI: func(ctrl *gomock.Controller) *gitlabmock.MockAPI {
+				api := gitlabmock.NewMockAPI(ctrl)
+				api.EXPECT().ListMergeRequestLabels(1, nil).Return(gitlab.Labels{"destroy", "moved"}, nil)
+				api.EXPECT().GetLabel("destroy").Return(&gitlab.Label{Name: "destroy"}, nil, nil)
+				api.EXPECT().GetLabel("moved").Return(&gitlab.Label{Name: "moved", Color: "#aaaaaa"}, nil, nil)
+				api.EXPECT().AddMergeRequestLabels(&[]string{"destroy"}, 1).Return(gitlab.Labels{"destroy", "moved"}, nil)
+				return api
+			},
+			result: terraform.ParseResult{
+				HasDestroy:     true,
+				MovedResources: []terraform.MovedResource{{From: "a.b", To: "a.c"}},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+			cfg := newFakeConfig()
+			cfg.ResultLabels = resultLabels
+			client, err := NewClient(cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			client.API = testCase.createMockGitLabAPI(mockCtrl)
+
+			if errMsgs := client.Notify.updateLabels(testCase.result); len(errMsgs) != 0 {
+				t.Errorf("got error messages %v", errMsgs)
+			}
+		})
+	}
+}
+
+func TestNotifyNotifyDiscussion(t *testing.T) {
+	t.Parallel()
+	const oldBody = "\n## Plan Result (foo)\n\nold plan"
+	testCases := []struct {
+		name                string
+		createMockGitLabAPI func(ctrl *gomock.Controller) *gitlabmock.MockAPI
+		patch               bool
+		autoResolve         bool
+		output              string
+		exitCode            int
+	}{
+		{
+			name: "create a discussion for the plan with destroy",
+			createMockGitLabAPI: func(ctrl *gomock.Controller) *gitlabmock.MockAPI {
+				api := gitlabmock.NewMockAPI(ctrl)
+				api.EXPECT().ListMergeRequestDiscussions(1, gomock.Any()).Return([]*gitlab.Discussion{}, &gitlab.Response{}, nil)
+				api.EXPECT().CreateMergeRequestDiscussion(1, gomock.Any()).Return(&gitlab.Discussion{ID: "new"}, nil, nil)
+				return api
+			},
+			autoResolve: true,
+			output:      "Plan: 1 to add, 0 to change, 1 to destroy.",
+		},
+		{
+			name: "create a discussion and resolve the discussions of the same target",
+			createMockGitLabAPI: func(ctrl *gomock.Controller) *gitlabmock.MockAPI {
+				api := gitlabmock.NewMockAPI(ctrl)
+				api.EXPECT().ListMergeRequestDiscussions(1, gomock.Any()).Return([]*gitlab.Discussion{
+					{ID: "old", Notes: []*gitlab.Note{{ID: 10, Body: oldBody}}},
+					{ID: "other", Notes: []*gitlab.Note{{ID: 11, Body: "\n## Plan Result (bar)\n"}}},
+					{ID: "individual", IndividualNote: true, Notes: []*gitlab.Note{{ID: 12, Body: oldBody}}},
+				}, &gitlab.Response{}, nil)
+				api.EXPECT().CreateMergeRequestDiscussion(1, gomock.Any()).Return(&gitlab.Discussion{ID: "new"}, nil, nil)
+				api.EXPECT().ResolveMergeRequestDiscussion(1, "new", &gitlab.ResolveMergeRequestDiscussionOptions{Resolved: gitlab.Ptr(true)}).Return(nil, nil, nil)
+				api.EXPECT().ResolveMergeRequestDiscussion(1, "old", &gitlab.ResolveMergeRequestDiscussionOptions{Resolved: gitlab.Ptr(true)}).Return(nil, nil, nil)
+				return api
+			},
+			autoResolve: true,
+			output:      "Plan: 1 to add, 0 to change, 0 to destroy.",
+		},
+		{
+			name: "patch the discussion and unresolve it because the plan has destroy",
+			createMockGitLabAPI: func(ctrl *gomock.Controller) *gitlabmock.MockAPI {
+				api := gitlabmock.NewMockAPI(ctrl)
+				api.EXPECT().ListMergeRequestDiscussions(1, gomock.Any()).Return([]*gitlab.Discussion{
+					{ID: "old", Notes: []*gitlab.Note{{ID: 10, Body: oldBody, Resolved: true}}},
+				}, &gitlab.Response{}, nil)
+				api.EXPECT().UpdateMergeRequestDiscussionNote(1, "old", 10, gomock.Any()).Return(nil, nil, nil)
+				api.EXPECT().ResolveMergeRequestDiscussion(1, "old", &gitlab.ResolveMergeRequestDiscussionOptions{Resolved: gitlab.Ptr(false)}).Return(nil, nil, nil)
+				return api
+			},
+			patch:       true,
+			autoResolve: true,
+			output:      "Plan: 0 to add, 0 to change, 1 to destroy.",
+		},
+		{
+			name: "patch the discussion without auto resolve",
+			createMockGitLabAPI: func(ctrl *gomock.Controller) *gitlabmock.MockAPI {
+				api := gitlabmock.NewMockAPI(ctrl)
+				api.EXPECT().ListMergeRequestDiscussions(1, gomock.Any()).Return([]*gitlab.Discussion{
+					{ID: "old", Notes: []*gitlab.Note{{ID: 10, Body: oldBody}}},
+				}, &gitlab.Response{}, nil)
+				api.EXPECT().UpdateMergeRequestDiscussionNote(1, "old", 10, gomock.Any()).Return(nil, nil, nil)
+				return api
+			},
+			patch:  true,
+			output: "Plan: 1 to add, 0 to change, 0 to destroy.",
+		},
+		{
+			name: "keep the discussion unresolved because the plan fails after the plan with destroy",
+			createMockGitLabAPI: func(ctrl *gomock.Controller) *gitlabmock.MockAPI {
+				api := gitlabmock.NewMockAPI(ctrl)
+				api.EXPECT().ListMergeRequestDiscussions(1, gomock.Any()).Return([]*gitlab.Discussion{
+					{ID: "old", Notes: []*gitlab.Note{{ID: 10, Body: oldBody}}},
+				}, &gitlab.Response{}, nil)
+				api.EXPECT().UpdateMergeRequestDiscussionNote(1, "old", 10, gomock.Any()).Return(nil, nil, nil)
+				return api
+			},
+			patch:       true,
+			autoResolve: true,
+			output:      "Error: hoge",
+			exitCode:    1,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+			cfg := newFakeConfig()
+			cfg.Vars = map[string]string{"target": "foo"}
+			cfg.Discussion = true
+			cfg.Patch = testCase.patch
+			cfg.AutoResolveDiscussion = testCase.autoResolve
+			client, err := NewClient(cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			client.API = testCase.createMockGitLabAPI(mockCtrl)
+
+			if _, err := client.Notify.Notify(notifier.ParamExec{CombinedOutput: testCase.output, ExitCode: testCase.exitCode}); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestNotifyNotifyOutdatedComment(t *testing.T) {
+	t.Parallel()
+	const oldBody = "\n## Plan Result (foo)\n\nold plan"
+	testCases := []struct {
+		name                string
+		createMockGitLabAPI func(ctrl *gomock.Controller) *gitlabmock.MockAPI
+		outdatedComment     string
+	}{
+		{
+			name: "hide the outdated comments",
+			createMockGitLabAPI: func(ctrl *gomock.Controller) *gitlabmock.MockAPI {
+				api := gitlabmock.NewMockAPI(ctrl)
+				api.EXPECT().ListMergeRequestNotes(1, gomock.Any()).Return([]*gitlab.Note{
+					{ID: 10, Body: oldBody},
+					{ID: 11, Body: "\n## Plan Result (bar)\n"},
+					{ID: 12, Body: hiddenCommentBody(oldBody)},
+				}, &gitlab.Response{}, nil)
+				api.EXPECT().CreateMergeRequestNote(1, gomock.Any()).Return(&gitlab.Note{}, nil, nil)
+				api.EXPECT().UpdateMergeRequestNote(1, 10, &gitlab.UpdateMergeRequestNoteOptions{Body: gitlab.Ptr(hiddenCommentBody(oldBody))}).Return(nil, nil, nil)
+				return api
+			},
+			outdatedComment: OutdatedCommentHide,
+		},
+		{
+			name: "delete the outdated comments",
+			createMockGitLabAPI: func(ctrl *gomock.Controller) *gitlabmock.MockAPI {
+				api := gitlabmock.NewMockAPI(ctrl)
+				api.EXPECT().ListMergeRequestNotes(1, gomock.Any()).Return([]*gitlab.Note{
+					{ID: 10, Body: oldBody},
+					{ID: 11, Body: "\n## Plan Result (bar)\n"},
+				}, &gitlab.Response{}, nil)
+				api.EXPECT().CreateMergeRequestNote(1, gomock.Any()).Return(&gitlab.Note{}, nil, nil)
+				api.EXPECT().DeleteMergeRequestNote(1, 10).Return(nil, nil)
+				return api
+			},
+			outdatedComment: OutdatedCommentDelete,
+		},
+		{
+			name: "keep the outdated comments",
+			createMockGitLabAPI: func(ctrl *gomock.Controller) *gitlabmock.MockAPI {
+				api := gitlabmock.NewMockAPI(ctrl)
+				api.EXPECT().CreateMergeRequestNote(1, gomock.Any()).Return(&gitlab.Note{}, nil, nil)
+				return api
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+			cfg := newFakeConfig()
+			cfg.Vars = map[string]string{"target": "foo"}
+			cfg.OutdatedComment = testCase.outdatedComment
+			client, err := NewClient(cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			client.API = testCase.createMockGitLabAPI(mockCtrl)
+
+			if _, err := client.Notify.Notify(notifier.ParamExec{CombinedOutput: "Plan: 1 to add, 0 to change, 0 to destroy."}); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestNotifyRender(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		name      string
+		template  string
+		paramExec notifier.ParamExec
+		exp       string
+		ok        bool
+	}{
+		{
+			name:      "the template is rendered without calling GitLab API",
+			template:  "{{.Vars.target}}: {{.Result}}",
+			paramExec: notifier.ParamExec{CombinedOutput: "Plan: 1 to add, 0 to change, 0 to destroy."},
+			exp:       "foo: Plan: 1 to add, 0 to change, 0 to destroy.",
+			ok:        true,
+		},
+		{
+			name:      "the parse error template is rendered if the output can't be parsed",
+			template:  "{{.Result}}",
+			paramExec: notifier.ParamExec{CombinedOutput: "unexpected output", ExitCode: 1},
+			exp:       "parse error (foo): 1",
+			ok:        true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+			cfg := newFakeConfig()
+			cfg.Vars = map[string]string{"target": "foo"}
+			cfg.Template = terraform.NewPlanTemplate(testCase.template)
+			cfg.ParseErrorTemplate = terraform.NewPlanParseErrorTemplate("parse error ({{.Vars.target}}): {{.ExitCode}}")
+			client, err := NewClient(cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			client.API = gitlabmock.NewMockAPI(mockCtrl)
+
+			body, err := client.Notify.Render(testCase.paramExec)
+			if (err == nil) != testCase.ok {
+				t.Fatalf("got error %v", err)
+			}
+			if body != testCase.exp {
+				t.Errorf("got %q but want %q", body, testCase.exp)
+			}
+		})
+	}
+}
+
+func TestNotifyPolicy(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		name      string
+		paramExec notifier.ParamExec
+		exitCode  int
+		exps      []string
+	}{
+		{
+			name: "the plan violates the policy",
+			paramExec: notifier.ParamExec{
+				CombinedOutput: `
+Terraform will perform the following actions:
+
+  # aws_db_instance.main will be destroyed
+  - resource "aws_db_instance" "main" {
+    }
+
+Plan: 0 to add, 0 to change, 1 to destroy.`,
+				ExitCode: 0,
+			},
+			exitCode: terraform.ExitFail,
+			exps: []string{
+				"[dry-run] add the labels policy-violation to the merge request !1\n",
+				"### :no_entry: Policy Violations\n",
+				"* protect-databases: delete aws_db_instance.main is denied\n",
+			},
+		},
+		{
+			name: "the plan doesn't violate the policy",
+			paramExec: notifier.ParamExec{
+				CombinedOutput: `
+Terraform will perform the following actions:
+
+  # aws_instance.web will be destroyed
+  - resource "aws_instance" "web" {
+    }
+
+Plan: 0 to add, 0 to change, 1 to destroy.`,
+				ExitCode: 0,
+			},
+			exitCode: terraform.ExitPass,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+			cfg := newFakeConfig()
+			cfg.ResultLabels = ResultLabels{PolicyViolationLabel: "policy-violation"}
+			cfg.Policy = []terraform.PolicyRule{
+				{Name: "protect-databases", Actions: []string{terraform.ActionDelete}, Resources: []string{"aws_db_instance.*"}},
+			}
+			buf := &bytes.Buffer{}
+			client := newDryRunClient(cfg, buf)
+
+			exitCode, err := client.Notify.Notify(testCase.paramExec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if exitCode != testCase.exitCode {
+				t.Errorf("got exit code %d but want %d", exitCode, testCase.exitCode)
+			}
+			for _, exp := range testCase.exps {
+				if !strings.Contains(buf.String(), exp) {
+					t.Errorf("the output doesn't contain %q:\n%s", exp, buf.String())
+				}
+			}
+			if len(testCase.exps) == 0 && strings.Contains(buf.String(), "Policy Violations") {
+				t.Errorf("the output contains the policy violations:\n%s", buf.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package gitlab
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/hirosassa/tfcmt-gitlab/pkg/terraform"
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+// planComment is the plan comment which the apply is based on
+type planComment struct {
+	note *gitlab.Note
+	meta *Metadata
+	// discussion is the ID of the thread which the plan comment starts. It's empty if the plan comment isn't a thread
+	discussion string
+}
+
+// resolveMergeRequest finds the merge request by the revision if the number isn't given, e.g. after the merge request is merged.
+// The number is kept in the config so that the merge request isn't looked up again when the comment is posted
+func (g *NotifyService) resolveMergeRequest() error {
+	cfg := &g.client.Config
+	if cfg.MR.Number != 0 || cfg.MR.Revision == "" {
+		return nil
+	}
+	numbers, err := g.client.Commits.ListMergeRequestIIDsByRevision(cfg.MR.Revision)
+	if err != nil {
+		return fmt.Errorf("list the merge requests of the revision: %w", err)
+	}
+	if len(numbers) != 0 {
+		cfg.MR.Number = numbers[0]
+	}
+	return nil
+}
+
+// findPlanComment returns the last plan comment of the same target on the merge request.
+// It returns nil if the plan comment isn't found.
+// The thread of the plan comment is looked up only in the discussion mode because the apply result is replied to it.
+func (g *NotifyService) findPlanComment() (*planComment, error) {
+	cfg := g.client.Config
+	number := cfg.MR.Number
+	if number == 0 {
+		return nil, nil
+	}
+
+	comments, err := g.client.Comment.List(number)
+	if err != nil {
+		return nil, fmt.Errorf("list the comments: %w", err)
+	}
+	want := g.newMetadata(commandPlan)
+	var plan *planComment
+	for _, comment := range comments {
+		if isHiddenComment(comment.Body) {
+			continue
+		}
+		// the later parts of the split plan comment have the parent
+		m := extractMetadata(comment.Body)
+		if m == nil || m.Parent != 0 || !want.IsSameTarget(m) {
+			continue
+		}
+		// the comments are sorted by the creation time in descending order by default, so the IDs are compared
+		if plan == nil || comment.ID > plan.note.ID {
+			plan = &planComment{note: comment, meta: m}
+		}
+	}
+	if plan == nil || !cfg.Discussion {
+		return plan, nil
+	}
+
+	discussions, err := g.client.Discussion.List(number)
+	if err != nil {
+		return plan, fmt.Errorf("list the discussions: %w", err)
+	}
+	for _, discussion := range discussions {
+		if slices.ContainsFunc(discussion.Notes, func(note *gitlab.Note) bool {
+			return note.ID == plan.note.ID
+		}) {
+			plan.discussion = discussion.ID
+			break
+		}
+	}
+	return plan, nil
+}
+
+// linkPlan sets the link to the plan comment and the differences between the planned changes and the applied changes.
+// The plan comments posted before the plan summary was embedded can't be compared
+func linkPlan(ct *terraform.CommonTemplate, plan *planComment, result terraform.ParseResult) {
+	ct.PlanLink = fmt.Sprintf("#note_%d", plan.note.ID)
+	if plan.meta.Plan == nil {
+		return
+	}
+	ct.PlanCompared = true
+	ct.PlanDiffs = terraform.CompareChanges(*plan.meta.Plan, terraform.AppliedChanges(result.AppliedResources))
+}
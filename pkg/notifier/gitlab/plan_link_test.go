@@ -0,0 +1,199 @@
+package gitlab
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hirosassa/tfcmt-gitlab/pkg/notifier"
+	gitlabmock "github.com/hirosassa/tfcmt-gitlab/pkg/notifier/gitlab/gen"
+	"github.com/hirosassa/tfcmt-gitlab/pkg/terraform"
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+	"go.uber.org/mock/gomock"
+)
+
+const planLinkApplyOutput = `null_resource.foo: Creating...
+null_resource.foo: Creation complete after 1s [id=1]
+null_resource.bar: Creating...
+null_resource.bar: Creation complete after 1s [id=2]
+
+Apply complete! Resources: 2 added, 0 changed, 0 destroyed.`
+
+func TestNotifyNotifyPlanLink(t *testing.T) {
+	t.Parallel()
+	planNotes := func(t *testing.T, addresses ...string) []*gitlab.Note {
+		t.Helper()
+		embed := func(m *Metadata) string {
+			body, err := m.Embed("plan")
+			if err != nil {
+				t.Fatal(err)
+			}
+			return body
+		}
+		plan := terraform.PlannedChanges(terraform.ParseResult{CreatedResources: addresses})
+		// the notes are listed in descending order like the GitLab API, so the plan comment isn't the last one
+		return []*gitlab.Note{
+			{ID: 7, Body: embed(&Metadata{Program: metadataProgram, Command: commandPlan, Target: "dev", Plan: &plan})},
+			{ID: 6, Body: embed(&Metadata{Program: metadataProgram, Command: commandPlan, Part: 2, Parent: 5})},
+			{ID: 5, Body: embed(&Metadata{Program: metadataProgram, Command: commandPlan, Part: 1, Plan: &plan})},
+			{ID: 3, Body: embed(&Metadata{Program: metadataProgram, Command: commandPlan})},
+			{ID: 2, Body: "not a tfcmt comment"},
+		}
+	}
+	assertDiff := func(t *testing.T, body string) {
+		t.Helper()
+		if !strings.Contains(body, "[The plan](#note_5)") {
+			t.Errorf("the apply result should link to the plan: %s", body)
+		}
+		if !strings.Contains(body, "* Applied but not planned\n  * create `null_resource.bar`") {
+			t.Errorf("the difference should be highlighted: %s", body)
+		}
+	}
+	testCases := []struct {
+		name                string
+		discussion          bool
+		number              int
+		createMockGitLabAPI func(t *testing.T, ctrl *gomock.Controller) *gitlabmock.MockAPI
+	}{
+		{
+			name:   "reference the plan comment",
+			number: 1,
+			createMockGitLabAPI: func(t *testing.T, ctrl *gomock.Controller) *gitlabmock.MockAPI {
+				t.Helper()
+				api := gitlabmock.NewMockAPI(ctrl)
+				api.EXPECT().ListMergeRequestNotes(1, gomock.Any()).Return(planNotes(t, "null_resource.foo"), &gitlab.Response{}, nil)
+				api.EXPECT().CreateMergeRequestNote(1, gomock.Any()).DoAndReturn(
+					func(_ int, opt *gitlab.CreateMergeRequestNoteOptions, _ ...gitlab.RequestOptionFunc) (*gitlab.Note, *gitlab.Response, error) {
+						assertDiff(t, *opt.Body)
+						return &gitlab.Note{ID: 8}, nil, nil
+					})
+				return api
+			},
+		},
+		{
+			name: "the merge request is looked up once after the merge",
+			createMockGitLabAPI: func(t *testing.T, ctrl *gomock.Controller) *gitlabmock.MockAPI {
+				t.Helper()
+				api := gitlabmock.NewMockAPI(ctrl)
+				api.EXPECT().ListMergeRequestsByCommit("abcd").Return([]*gitlab.BasicMergeRequest{{IID: 1}}, nil, nil)
+				api.EXPECT().ListMergeRequestNotes(1, gomock.Any()).Return(planNotes(t, "null_resource.foo", "null_resource.bar"), &gitlab.Response{}, nil)
+				api.EXPECT().CreateMergeRequestNote(1, gomock.Any()).DoAndReturn(
+					func(_ int, opt *gitlab.CreateMergeRequestNoteOptions, _ ...gitlab.RequestOptionFunc) (*gitlab.Note, *gitlab.Response, error) {
+						if !strings.Contains(*opt.Body, "[The plan](#note_5) was applied. The applied changes match the plan.") {
+							t.Errorf("the apply result should match the plan: %s", *opt.Body)
+						}
+						return &gitlab.Note{ID: 8}, nil, nil
+					})
+				return api
+			},
+		},
+		{
+			name:   "the different resources are listed even if the numbers match",
+			number: 1,
+			createMockGitLabAPI: func(t *testing.T, ctrl *gomock.Controller) *gitlabmock.MockAPI {
+				t.Helper()
+				api := gitlabmock.NewMockAPI(ctrl)
+				api.EXPECT().ListMergeRequestNotes(1, gomock.Any()).Return(planNotes(t, "null_resource.foo", "null_resource.baz"), &gitlab.Response{}, nil)
+				api.EXPECT().CreateMergeRequestNote(1, gomock.Any()).DoAndReturn(
+					func(_ int, opt *gitlab.CreateMergeRequestNoteOptions, _ ...gitlab.RequestOptionFunc) (*gitlab.Note, *gitlab.Response, error) {
+						for _, s := range []string{
+							"* Applied but not planned\n  * create `null_resource.bar`",
+							"* Planned but not applied\n  * create `null_resource.baz`",
+						} {
+							if !strings.Contains(*opt.Body, s) {
+								t.Errorf("the difference should be listed: %s", *opt.Body)
+							}
+						}
+						return &gitlab.Note{ID: 8}, nil, nil
+					})
+				return api
+			},
+		},
+		{
+			name:       "reply to the thread of the plan",
+			discussion: true,
+			number:     1,
+			createMockGitLabAPI: func(t *testing.T, ctrl *gomock.Controller) *gitlabmock.MockAPI {
+				t.Helper()
+				api := gitlabmock.NewMockAPI(ctrl)
+				api.EXPECT().ListMergeRequestNotes(1, gomock.Any()).Return(planNotes(t, "null_resource.foo"), &gitlab.Response{}, nil)
+				api.EXPECT().ListMergeRequestDiscussions(1, gomock.Any()).Return([]*gitlab.Discussion{
+					{ID: "other", Notes: []*gitlab.Note{{ID: 3}}},
+					{ID: "plan", Notes: []*gitlab.Note{{ID: 5}, {ID: 4}}},
+				}, &gitlab.Response{}, nil)
+				api.EXPECT().AddMergeRequestDiscussionNote(1, "plan", gomock.Any()).DoAndReturn(
+					func(_ int, _ string, opt *gitlab.AddMergeRequestDiscussionNoteOptions, _ ...gitlab.RequestOptionFunc) (*gitlab.Note, *gitlab.Response, error) {
+						assertDiff(t, *opt.Body)
+						if m := extractMetadata(*opt.Body); m == nil || m.Command != commandApply {
+							t.Errorf("invalid metadata: %+v", m)
+						}
+						return &gitlab.Note{ID: 8}, nil, nil
+					})
+				return api
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+			cfg := newFakeConfig()
+			cfg.MR.Number = testCase.number
+			cfg.Parser = terraform.NewApplyParser()
+			cfg.Template = terraform.NewApplyTemplate(terraform.DefaultApplyTemplate)
+			cfg.Discussion = testCase.discussion
+			cfg.LinkPlan = true
+			client, err := NewClient(cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			client.API = testCase.createMockGitLabAPI(t, mockCtrl)
+
+			if _, err := client.Notify.Notify(notifier.ParamExec{CombinedOutput: planLinkApplyOutput}); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestNotifyNotifyPlanSummary(t *testing.T) {
+	t.Parallel()
+	cfg := newFakeConfig()
+	client, err := NewClient(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	api := gitlabmock.NewMockAPI(mockCtrl)
+	api.EXPECT().CreateMergeRequestNote(1, gomock.Any()).DoAndReturn(
+		func(_ int, opt *gitlab.CreateMergeRequestNoteOptions, _ ...gitlab.RequestOptionFunc) (*gitlab.Note, *gitlab.Response, error) {
+			m := extractMetadata(*opt.Body)
+			if m == nil || m.Plan == nil {
+				t.Fatalf("the plan summary should be embedded: %s", *opt.Body)
+			}
+			exp := terraform.PlannedChanges(terraform.ParseResult{CreatedResources: []string{"null_resource.foo"}})
+			if diff := cmp.Diff(&exp, m.Plan); diff != "" {
+				t.Error(diff)
+			}
+			if _, ok := m.Plan.Changes["null_resource.foo"]; !ok {
+				t.Errorf("the addresses should be embedded: %s", *opt.Body)
+			}
+			return &gitlab.Note{ID: 1}, nil, nil
+		})
+	client.API = api
+
+	if _, err := client.Notify.Notify(notifier.ParamExec{CombinedOutput: `Terraform will perform the following actions:
+
+  # null_resource.foo will be created
+  + resource "null_resource" "foo" {}
+
+Plan: 1 to add, 0 to change, 0 to destroy.`}); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,60 @@
+package gitlab
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+
+	"github.com/hirosassa/tfcmt-gitlab/pkg/terraform"
+)
+
+// TerraformReport is the report for the Terraform widget of GitLab merge requests
+// https://docs.gitlab.com/ee/ci/yaml/artifacts_reports.html#artifactsreportsterraform
+type TerraformReport struct {
+	Create  int    `json:"create"`
+	Update  int    `json:"update"`
+	Delete  int    `json:"delete"`
+	JobName string `json:"job_name,omitempty"`
+	JobPath string `json:"job_path,omitempty"`
+}
+
+// newTerraformReport returns the report of the plan result.
+// A replaced resource is counted as both created and deleted like terraform plan.
+func (g *NotifyService) newTerraformReport(results ...terraform.ParseResult) TerraformReport {
+	cfg := g.client.Config
+	report := TerraformReport{
+		JobName: cfg.JobName,
+	}
+	if u, err := url.Parse(cfg.CI); err == nil {
+		report.JobPath = u.Path
+	}
+	for _, result := range results {
+		report.Create += len(result.CreatedResources) + len(result.ReplacedResources)
+		report.Update += len(result.UpdatedResources)
+		report.Delete += len(result.DeletedResources) + len(result.ReplacedResources)
+	}
+	return report
+}
+
+// writeTerraformReport writes the report of the plan result if TerraformReportPath is set.
+// The report isn't written if any plan fails because the counts of the changes are unknown.
+func (g *NotifyService) writeTerraformReport(results ...terraform.ParseResult) error {
+	path := g.client.Config.TerraformReportPath
+	if path == "" {
+		return nil
+	}
+	for _, result := range results {
+		if result.HasPlanError || result.HasParseError {
+			return nil
+		}
+	}
+	b, err := json.Marshal(g.newTerraformReport(results...))
+	if err != nil {
+		return fmt.Errorf("marshal the terraform report as JSON: %w", err)
+	}
+	if err := os.WriteFile(path, b, 0o600); err != nil { //nolint:gomnd
+		return fmt.Errorf("write the terraform report to a file %s: %w", path, err)
+	}
+	return nil
+}
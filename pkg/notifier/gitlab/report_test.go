@@ -0,0 +1,84 @@
+package gitlab
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hirosassa/tfcmt-gitlab/pkg/terraform"
+)
+
+func TestNotifyWriteTerraformReport(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		name    string
+		results []terraform.ParseResult
+		exp     string
+	}{
+		{
+			name: "replaced resources are counted as created and deleted",
+			results: []terraform.ParseResult{
+				{
+					CreatedResources:  []string{"null_resource.a"},
+					UpdatedResources:  []string{"null_resource.b", "null_resource.c"},
+					DeletedResources:  []string{"null_resource.d"},
+					ReplacedResources: []string{"null_resource.e"},
+				},
+			},
+			exp: `{"create":2,"update":2,"delete":2,"job_name":"plan","job_path":"/owner/repo/-/jobs/1"}`,
+		},
+		{
+			name: "the results of multiple targets are aggregated",
+			results: []terraform.ParseResult{
+				{CreatedResources: []string{"null_resource.a"}},
+				{DeletedResources: []string{"null_resource.a"}},
+				{HasNoChanges: true},
+			},
+			exp: `{"create":1,"update":0,"delete":1,"job_name":"plan","job_path":"/owner/repo/-/jobs/1"}`,
+		},
+		{
+			name: "the report isn't written if the plan fails",
+			results: []terraform.ParseResult{
+				{CreatedResources: []string{"null_resource.a"}},
+				{HasPlanError: true},
+			},
+		},
+		{
+			name: "the report isn't written if the output can't be parsed",
+			results: []terraform.ParseResult{
+				{HasParseError: true},
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+			path := filepath.Join(t.TempDir(), "report.json")
+			cfg := newFakeConfig()
+			cfg.TerraformReportPath = path
+			cfg.JobName = "plan"
+			cfg.CI = "https://gitlab.example.com/owner/repo/-/jobs/1"
+			client, err := NewClient(cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := client.Notify.writeTerraformReport(testCase.results...); err != nil {
+				t.Fatal(err)
+			}
+			b, err := os.ReadFile(path)
+			if testCase.exp == "" {
+				if !os.IsNotExist(err) {
+					t.Errorf("the report shouldn't be written: %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(b) != testCase.exp {
+				t.Errorf("wanted %s, got %s", testCase.exp, string(b))
+			}
+		})
+	}
+}
@@ -0,0 +1,297 @@
+package gitlab
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/hirosassa/tfcmt-gitlab/pkg/terraform"
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+const (
+	// defaultMaxCommentLength is the maximum length of GitLab note
+	defaultMaxCommentLength = 1000000
+	// splitMargin is reserved in each note for the links between the notes and the metadata
+	splitMargin = 10000
+)
+
+// split splits the body into multiple parts if splitting comments is enabled and the body is too long
+func (g *NotifyService) split(body string) []string {
+	cfg := g.client.Config
+	if !cfg.SplitComment || !cfg.MR.IsNumber() {
+		return []string{body}
+	}
+	maxLength := cfg.MaxCommentLength
+	if maxLength <= 0 {
+		maxLength = defaultMaxCommentLength
+	}
+	return splitBody(body, maxLength-splitMargin)
+}
+
+// findSeries returns the latest comment of the same target and its continuation comments in order
+func (g *NotifyService) findSeries(template *terraform.Template, command string, comments []*gitlab.Note) []*gitlab.Note {
+	var head *gitlab.Note
+	for _, comment := range comments {
+		if !g.isSameTarget(template, command, comment.Body) {
+			continue
+		}
+		if m := extractMetadata(comment.Body); m != nil && m.Parent != 0 {
+			continue
+		}
+		// the comments are sorted by the creation time in descending order by default, so the IDs are compared
+		if head == nil || comment.ID > head.ID {
+			head = comment
+		}
+	}
+	if head == nil {
+		return nil
+	}
+
+	type part struct {
+		note *gitlab.Note
+		num  int
+	}
+	var parts []part
+	for _, comment := range comments {
+		if !g.isSameTarget(template, command, comment.Body) {
+			continue
+		}
+		if m := extractMetadata(comment.Body); m != nil && m.Parent == head.ID {
+			parts = append(parts, part{note: comment, num: m.Part})
+		}
+	}
+	sort.SliceStable(parts, func(i, j int) bool {
+		return parts[i].num < parts[j].num
+	})
+
+	series := []*gitlab.Note{head}
+	for _, p := range parts {
+		series = append(series, p.note)
+	}
+	return series
+}
+
+// postSeries posts the parts as numbered notes which are linked to each other.
+// The existing notes are updated in order, and the surplus existing notes are deleted.
+func (g *NotifyService) postSeries(meta *Metadata, parts []string, existing []*gitlab.Note) error { //nolint:cyclop
+	cfg := g.client.Config
+	opt := PostOptions{
+		Number:   cfg.MR.Number,
+		Revision: cfg.MR.Revision,
+	}
+	n := len(parts)
+	ids := make([]int, n)
+	bodies := make([]string, n)
+	metas := make([]*Metadata, n)
+	var withoutNextLink []int
+
+	for i, part := range parts {
+		m := *meta
+		if n > 1 {
+			m.Part = i + 1
+		}
+		if i > 0 {
+			m.Parent = int64(ids[0])
+			part = fmt.Sprintf("> Part %d of %d. Continued from [part %d](#note_%d).\n\n", i+1, n, i, ids[i-1]) + part
+		}
+		bodies[i] = part
+		metas[i] = &m
+
+		body := part
+		if i < n-1 {
+			if i+1 < len(existing) {
+				body += nextLink(i+2, int(existing[i+1].ID))
+			} else {
+				withoutNextLink = append(withoutNextLink, i)
+			}
+		}
+		body, err := m.Embed(body)
+		if err != nil {
+			return err
+		}
+
+		if i < len(existing) {
+			ids[i] = int(existing[i].ID)
+			if err := g.client.Comment.Patch(ids[i], body, opt); err != nil {
+				return err
+			}
+			continue
+		}
+		note, err := g.client.Comment.PostNote(body, cfg.MR.Number)
+		if err != nil {
+			return err
+		}
+		ids[i] = int(note.ID)
+	}
+
+	// the IDs of the new notes are known after posting them
+	for _, i := range withoutNextLink {
+		body, err := metas[i].Embed(bodies[i] + nextLink(i+2, ids[i+1]))
+		if err != nil {
+			return err
+		}
+		if err := g.client.Comment.Patch(ids[i], body, opt); err != nil {
+			return err
+		}
+	}
+
+	for i := n; i < len(existing); i++ {
+		if err := g.client.Comment.Delete(int(existing[i].ID), opt); err != nil {
+			return fmt.Errorf("delete a surplus part of the comment (%d): %w", existing[i].ID, err)
+		}
+	}
+	return nil
+}
+
+func nextLink(part, note int) string {
+	return fmt.Sprintf("\n\n> Continued in [part %d](#note_%d).", part, note)
+}
+
+// splitState tracks the blocks which are open at the current line of the body.
+// The blocks are closed at the end of a part and reopened at the beginning of the next part.
+type splitState struct {
+	// fence is the opening line of the code fence such as "```hcl". It's empty outside of the code fence
+	fence string
+	// pre is true inside of <pre><code>
+	pre bool
+	// details is the opening lines of the <details> tags
+	details []string
+}
+
+func (s *splitState) update(line string) {
+	t := strings.TrimSpace(line)
+	if s.fence != "" {
+		if strings.HasPrefix(t, "```") && strings.Trim(t, "`") == "" {
+			s.fence = ""
+		}
+		return
+	}
+	if s.pre {
+		if strings.Contains(t, "</code></pre>") {
+			s.pre = false
+		}
+		return
+	}
+	if strings.HasPrefix(t, "```") {
+		s.fence = t
+		return
+	}
+	if i := strings.LastIndex(t, "<pre><code>"); i != -1 && !strings.Contains(t[i:], "</code></pre>") {
+		s.pre = true
+	}
+	for i := strings.Count(t, "<details"); i > 0; i-- {
+		s.details = append(s.details, t)
+	}
+	for i := strings.Count(t, "</details>"); i > 0 && len(s.details) > 0; i-- {
+		s.details = s.details[:len(s.details)-1]
+	}
+}
+
+func (s *splitState) clone() *splitState {
+	c := *s
+	c.details = append([]string(nil), s.details...)
+	return &c
+}
+
+func (s *splitState) closing() string {
+	var b strings.Builder
+	if s.fence != "" {
+		b.WriteString("```\n")
+	}
+	if s.pre {
+		b.WriteString("</code></pre>\n")
+	}
+	for range s.details {
+		b.WriteString("</details>\n")
+	}
+	return b.String()
+}
+
+func (s *splitState) opening() string {
+	var b strings.Builder
+	for _, d := range s.details {
+		b.WriteString(d + "\n")
+	}
+	if s.pre {
+		b.WriteString("<pre><code>")
+	}
+	if s.fence != "" {
+		b.WriteString(s.fence + "\n")
+	}
+	return b.String()
+}
+
+// splitBody splits the body into parts whose length is at most size.
+// The body is split at line boundaries if possible,
+// and code blocks and <details> tags which are open at the split point are closed and reopened.
+func splitBody(body string, size int) []string {
+	if len(body) <= size {
+		return []string{body}
+	}
+	var parts []string
+	var cur strings.Builder
+	state := &splitState{}
+	start := 0 // the length of the reopened blocks at the beginning of the current part
+
+	flush := func() {
+		s := cur.String()
+		if closing := state.closing(); closing != "" {
+			if !strings.HasSuffix(s, "\n") {
+				s += "\n"
+			}
+			s += closing
+		}
+		parts = append(parts, s)
+		cur.Reset()
+		opening := state.opening()
+		cur.WriteString(opening)
+		start = len(opening)
+	}
+
+	for _, line := range strings.SplitAfter(body, "\n") {
+		// the blocks which are open after the line have to be closed if the part ends with the line
+		next := state.clone()
+		next.update(line)
+		rest := line
+		for rest != "" {
+			room := size - cur.Len() - len(next.closing())
+			if !strings.HasSuffix(rest, "\n") {
+				// a newline is added before the closing blocks
+				room--
+			}
+			if len(rest) <= room {
+				cur.WriteString(rest)
+				break
+			}
+			if cur.Len() > start {
+				flush()
+				continue
+			}
+			// the line is too long to fit in a part, so it's split.
+			// The rest of the line is written to the next part, so the blocks open before the line are closed.
+			n := size - cur.Len() - len(state.closing()) - 1
+			if n >= len(rest) {
+				// the line opens a block, so the whole line doesn't fit with the closing of the block
+				n = len(rest) - 1
+			}
+			for n > 0 && !utf8.RuneStart(rest[n]) {
+				n--
+			}
+			if n <= 0 {
+				n = len(rest)
+			}
+			cur.WriteString(rest[:n])
+			rest = rest[n:]
+			if rest != "" {
+				flush()
+			}
+		}
+		state = next
+	}
+	if cur.Len() > start || len(parts) == 0 {
+		parts = append(parts, cur.String())
+	}
+	return parts
+}
@@ -0,0 +1,184 @@
+package gitlab
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hirosassa/tfcmt-gitlab/pkg/notifier"
+	gitlabmock "github.com/hirosassa/tfcmt-gitlab/pkg/notifier/gitlab/gen"
+	"github.com/hirosassa/tfcmt-gitlab/pkg/terraform"
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+	"go.uber.org/mock/gomock"
+)
+
+func TestSplitBody(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		name string
+		body string
+		size int
+		exp  []string
+	}{
+		{
+			name: "short body",
+			body: "foo\nbar\n",
+			size: 100,
+			exp:  []string{"foo\nbar\n"},
+		},
+		{
+			name: "split at line boundaries",
+			body: "aaaa\nbbbb\ncccc\n",
+			size: 12,
+			exp:  []string{"aaaa\nbbbb\n", "cccc\n"},
+		},
+		{
+			name: "close and reopen the code block and details",
+			body: "# title\n<details><summary>Result</summary>\n\n```hcl\naaaa\nbbbb\n```\n</details>\n",
+			size: 71,
+			exp: []string{
+				"# title\n<details><summary>Result</summary>\n\n```hcl\naaaa\n```\n</details>\n",
+				"<details><summary>Result</summary>\n```hcl\nbbbb\n```\n</details>\n",
+			},
+		},
+		{
+			name: "close and reopen pre",
+			body: "<pre><code>aaaa\nbbbb\ncccc</code></pre>\n",
+			size: 32,
+			exp: []string{
+				"<pre><code>aaaa\n</code></pre>\n",
+				"<pre><code>bbbb\n</code></pre>\n",
+				"<pre><code>cccc</code></pre>\n",
+			},
+		},
+		{
+			name: "split a long line",
+			body: strings.Repeat("a", 10) + "\n",
+			size: 5,
+			exp:  []string{"aaaa", "aaaa", "aa\n"},
+		},
+		{
+			name: "split a long line which opens a code block",
+			body: "```hcl\naa\n```\n",
+			size: 10,
+			exp:  []string{"```hcl", "\naa\n```\n"},
+		},
+	}
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+			parts := splitBody(testCase.body, testCase.size)
+			if diff := cmp.Diff(testCase.exp, parts); diff != "" {
+				t.Error(diff)
+			}
+			for _, part := range parts {
+				if len(part) > testCase.size {
+					t.Errorf("the length of the part %q exceeds %d", part, testCase.size)
+				}
+			}
+		})
+	}
+}
+
+func TestNotifyNotifySplitComment(t *testing.T) {
+	t.Parallel()
+	output := "Plan: 1 to add, 0 to change, 0 to destroy.\n" + strings.Repeat("# null_resource.foo will be created\n", 10)
+	testCases := []struct {
+		name                string
+		createMockGitLabAPI func(ctrl *gomock.Controller, client *Client) *gitlabmock.MockAPI
+		patch               bool
+	}{
+		{
+			name: "post a series of notes",
+			createMockGitLabAPI: func(ctrl *gomock.Controller, _ *Client) *gitlabmock.MockAPI {
+				api := gitlabmock.NewMockAPI(ctrl)
+				api.EXPECT().CreateMergeRequestNote(1, gomock.Any()).Return(&gitlab.Note{ID: 10}, nil, nil)
+				api.EXPECT().CreateMergeRequestNote(1, gomock.Any()).DoAndReturn(func(_ int, opt *gitlab.CreateMergeRequestNoteOptions, _ ...gitlab.RequestOptionFunc) (*gitlab.Note, *gitlab.Response, error) {
+					if !strings.Contains(*opt.Body, "Continued from [part 1](#note_10)") {
+						t.Errorf("the second part should be linked to the first part: %s", *opt.Body)
+					}
+					if m := extractMetadata(*opt.Body); m == nil || m.Part != 2 || m.Parent != 10 {
+						t.Errorf("invalid metadata: %+v", m)
+					}
+					return &gitlab.Note{ID: 11}, nil, nil
+				})
+				api.EXPECT().UpdateMergeRequestNote(1, 10, gomock.Any()).DoAndReturn(func(_, _ int, opt *gitlab.UpdateMergeRequestNoteOptions, _ ...gitlab.RequestOptionFunc) (*gitlab.Note, *gitlab.Response, error) {
+					if !strings.Contains(*opt.Body, "Continued in [part 2](#note_11)") {
+						t.Errorf("the first part should be linked to the second part: %s", *opt.Body)
+					}
+					return nil, nil, nil
+				})
+				return api
+			},
+		},
+		{
+			name:  "patch the series and delete the surplus part",
+			patch: true,
+			createMockGitLabAPI: func(ctrl *gomock.Controller, client *Client) *gitlabmock.MockAPI {
+				head := client.Notify.newMetadata(commandPlan)
+				head.Part = 1
+				headBody, _ := head.Embed("part 1")
+				parts := []*gitlab.Note{{ID: 10, Body: headBody}}
+				for i, id := range []int64{11, 12} {
+					m := client.Notify.newMetadata(commandPlan)
+					m.Part = i + 2
+					m.Parent = 10
+					b, _ := m.Embed("part")
+					parts = append(parts, &gitlab.Note{ID: id, Body: b})
+				}
+				api := gitlabmock.NewMockAPI(ctrl)
+				api.EXPECT().ListMergeRequestNotes(1, gomock.Any()).Return([]*gitlab.Note{parts[2], parts[0], parts[1]}, &gitlab.Response{}, nil)
+				api.EXPECT().UpdateMergeRequestNote(1, 10, gomock.Any()).Return(nil, nil, nil)
+				api.EXPECT().UpdateMergeRequestNote(1, 11, gomock.Any()).Return(nil, nil, nil)
+				api.EXPECT().DeleteMergeRequestNote(1, 12).Return(nil, nil)
+				return api
+			},
+		},
+		{
+			name:  "patch the latest series when the notes are listed in descending order",
+			patch: true,
+			createMockGitLabAPI: func(ctrl *gomock.Controller, client *Client) *gitlabmock.MockAPI {
+				note := func(id int64, part int, parent int64) *gitlab.Note {
+					m := client.Notify.newMetadata(commandPlan)
+					m.Part = part
+					m.Parent = parent
+					b, _ := m.Embed("part")
+					return &gitlab.Note{ID: id, Body: b}
+				}
+				api := gitlabmock.NewMockAPI(ctrl)
+				api.EXPECT().ListMergeRequestNotes(1, gomock.Any()).Return([]*gitlab.Note{
+					note(11, 2, 10), note(10, 1, 0), note(4, 2, 3), note(3, 1, 0),
+				}, &gitlab.Response{}, nil)
+				api.EXPECT().UpdateMergeRequestNote(1, 10, gomock.Any()).Return(nil, nil, nil)
+				api.EXPECT().UpdateMergeRequestNote(1, 11, gomock.Any()).Return(nil, nil, nil)
+				return api
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+			cfg := newFakeConfig()
+			cfg.Template = terraform.NewPlanTemplate("{{.CombinedOutput}}")
+			cfg.Template.MaxCodeLength = terraform.NoCodeLengthLimit
+			cfg.SplitComment = true
+			cfg.MaxCommentLength = splitMargin + len(output)/2 + 50
+			cfg.Patch = testCase.patch
+			client, err := NewClient(cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			client.API = testCase.createMockGitLabAPI(mockCtrl, client)
+
+			if _, err := client.Notify.Notify(notifier.ParamExec{CombinedOutput: output}); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
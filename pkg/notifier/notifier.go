@@ -1,6 +1,7 @@
 package notifier
 
 import (
+	"errors"
 	"os/exec"
 )
 
@@ -9,6 +10,51 @@ type Notifier interface {
 	Notify(param ParamExec) (int, error)
 }
 
+// MultiNotifier is a notification interface for the results of multiple targets
+type MultiNotifier interface {
+	NotifyAll(params []ParamExec) (int, error)
+}
+
+// Notifiers fans out the notification to multiple notifiers.
+// The exit code of the first notifier is returned and the errors of all notifiers are joined.
+type Notifiers []Notifier
+
+// Notify sends the notification with all notifiers
+func (ns Notifiers) Notify(param ParamExec) (int, error) {
+	exitCode := param.ExitCode
+	var errs []error
+	for i, n := range ns {
+		code, err := n.Notify(param)
+		if i == 0 {
+			exitCode = code
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return exitCode, errors.Join(errs...)
+}
+
+// MultiNotifiers fans out the notification of multiple targets to multiple notifiers.
+// The exit code of the first notifier is returned and the errors of all notifiers are joined.
+type MultiNotifiers []MultiNotifier
+
+// NotifyAll sends the notification with all notifiers
+func (ns MultiNotifiers) NotifyAll(params []ParamExec) (int, error) {
+	exitCode := 0
+	var errs []error
+	for i, n := range ns {
+		code, err := n.NotifyAll(params)
+		if i == 0 {
+			exitCode = code
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return exitCode, errors.Join(errs...)
+}
+
 type ParamExec struct {
 	Stdout         string
 	Stderr         string
@@ -16,4 +62,8 @@ type ParamExec struct {
 	CIName         string
 	Cmd            *exec.Cmd
 	ExitCode       int
+	// Target is the name of the target when the command is run for multiple targets
+	Target string
+	// PlanJSON is the JSON representation of the plan (`terraform show -json <plan file>`)
+	PlanJSON string
 }
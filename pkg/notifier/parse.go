@@ -0,0 +1,59 @@
+package notifier
+
+import "github.com/hirosassa/tfcmt-gitlab/pkg/terraform"
+
+// ParseOption is the option to parse the output of the command
+type ParseOption struct {
+	Parser terraform.Parser
+	// DetailedExitCode means the exit code of terraform plan follows `-detailed-exitcode`
+	DetailedExitCode bool
+	// ChangesExitCode is the exit code which tfcmt returns when the plan has changes with DetailedExitCode
+	ChangesExitCode int
+	// Policy is the rules of the changes which aren't allowed
+	Policy []terraform.PolicyRule
+}
+
+// Parse parses the output of the command and maps the exit code of the command into the result.
+// All notifiers parse the output with it so that they report the same result
+func Parse(param ParamExec, opt ParseOption) terraform.ParseResult {
+	parser := opt.Parser
+
+	output := param.CombinedOutput
+	if param.PlanJSON != "" {
+		output = param.PlanJSON
+	}
+	result := parser.Parse(output)
+	result.ExitCode = param.ExitCode
+	if IsPlanParser(parser) && !result.HasParseError {
+		result.PolicyViolations = terraform.EvaluatePolicy(opt.Policy, result)
+	}
+	if opt.DetailedExitCode && IsPlanParser(parser) {
+		// https://developer.hashicorp.com/terraform/cli/commands/plan#detailed-exitcode
+		switch param.ExitCode {
+		case terraform.ExitPass:
+			result.HasChanges = false
+		case terraform.ExitChanges:
+			result.HasChanges = true
+			result.ExitCode = opt.ChangesExitCode
+		}
+	}
+	return result
+}
+
+// PolicyExitCode returns ExitFail if the plan violates the policy so that the pipeline fails
+func PolicyExitCode(exitCode int, result terraform.ParseResult) int {
+	if len(result.PolicyViolations) != 0 {
+		return terraform.ExitFail
+	}
+	return exitCode
+}
+
+// IsPlanParser returns true if the parser parses the result of terraform plan
+func IsPlanParser(parser terraform.Parser) bool {
+	switch parser.(type) {
+	case *terraform.PlanParser, *terraform.JSONPlanParser, *terraform.TerragruntPlanParser:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,124 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/hirosassa/tfcmt-gitlab/pkg/terraform"
+)
+
+const (
+	// TypeSlack posts a message to Slack incoming webhook
+	TypeSlack = "slack"
+	// TypeGeneric posts a JSON to any webhook
+	TypeGeneric = "webhook"
+)
+
+const defaultTimeout = 30 * time.Second
+
+// Client is a webhook client
+type Client struct {
+	Config     Config
+	HTTPClient *http.Client
+	ctx        context.Context
+}
+
+// Config is a configuration for webhook client
+type Config struct {
+	// Type is either TypeSlack or TypeGeneric
+	Type string
+	// URL is the URL of the webhook. "$NAME" means the environment variable NAME
+	URL string
+	// Headers are added to the request. "$NAME" in values means the environment variable NAME
+	Headers map[string]string
+	// Command is the name of the command such as plan and apply
+	Command string
+	Parser  terraform.Parser
+	// DetailedExitCode means the exit code of terraform plan follows `-detailed-exitcode`
+	DetailedExitCode bool
+	// ChangesExitCode is the exit code which tfcmt returns when the plan has changes with DetailedExitCode
+	ChangesExitCode int
+	// Policy is the rules of the changes which aren't allowed. The violations fail the command
+	Policy []terraform.PolicyRule
+	// Tool is the tool which runs the command such as terraform.ToolOpenTofu.
+	// If it's empty, the tool is detected from the output
+	Tool string
+	// Template renders the body. If it's nil, the default message (Slack) or JSON (generic webhook) is posted
+	Template *terraform.Template
+	// OnlyFailure means the notification is sent only when the command fails
+	OnlyFailure bool
+	CI          string
+	Vars        map[string]string
+	Templates   map[string]string
+}
+
+// NewClient returns Client initialized with Config
+func NewClient(cfg Config) (*Client, error) {
+	switch cfg.Type {
+	case TypeSlack, TypeGeneric:
+	default:
+		return nil, fmt.Errorf("webhook type must be either %s or %s: %s", TypeSlack, TypeGeneric, cfg.Type)
+	}
+	cfg.URL = expandEnv(cfg.URL)
+	if cfg.URL == "" {
+		return nil, errors.New("webhook url is missing")
+	}
+	headers := make(map[string]string, len(cfg.Headers))
+	for k, v := range cfg.Headers {
+		headers[k] = expandEnv(v)
+	}
+	cfg.Headers = headers
+	return &Client{
+		Config: cfg,
+		HTTPClient: &http.Client{
+			Timeout: defaultTimeout,
+		},
+	}, nil
+}
+
+// WithContext returns a shallow copy of the client which sends the requests with ctx,
+// so that the requests are canceled with the command
+func (c *Client) WithContext(ctx context.Context) *Client {
+	c2 := *c
+	c2.ctx = ctx
+	return &c2
+}
+
+func expandEnv(s string) string {
+	if strings.HasPrefix(s, "$") {
+		return os.Getenv(strings.TrimPrefix(s, "$"))
+	}
+	return s
+}
+
+func (c *Client) post(body []byte) error {
+	ctx := c.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.Config.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("create a webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range c.Config.Headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("send a webhook request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 1024)) //nolint:gomnd
+		return fmt.Errorf("webhook returned status code %d: %s", resp.StatusCode, string(b))
+	}
+	return nil
+}
@@ -0,0 +1,249 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hirosassa/tfcmt-gitlab/pkg/notifier"
+	"github.com/hirosassa/tfcmt-gitlab/pkg/terraform"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultSlackPlanTemplate is a default Slack message for terraform plan
+	DefaultSlackPlanTemplate = "{{if eq .ExitCode 1}}:x: Plan Failed{{else}}Plan Result{{end}}{{if .Vars.target}} ({{.Vars.target}}){{end}}{{if .Link}} <{{.Link}}|CI link>{{end}}\n" +
+		"{{if .HasDestroy}}:warning: This plan contains resource delete operation.\n{{end}}" +
+		"{{if .Result}}```\n{{.Result}}\n```{{else}}It failed to parse the result.{{end}}"
+
+	// DefaultSlackApplyTemplate is a default Slack message for terraform apply
+	DefaultSlackApplyTemplate = "{{if eq .ExitCode 0}}:white_check_mark: Apply Succeeded{{else}}:x: Apply Failed{{end}}{{if .Vars.target}} ({{.Vars.target}}){{end}}{{if .Link}} <{{.Link}}|CI link>{{end}}\n" +
+		"{{if .Result}}```\n{{.Result}}\n```{{else}}It failed to parse the result.{{end}}"
+
+	// DefaultSlackDriftTemplate is a default Slack message for the drift detection
+	DefaultSlackDriftTemplate = "{{if eq .ExitCode 1}}:x: Drift Detection Failed{{else if or .DriftedResources .HasChanges}}:warning: Drift Detected{{else}}No Drift{{end}}{{if .Vars.target}} ({{.Vars.target}}){{end}}{{if .Link}} <{{.Link}}|CI link>{{end}}\n" +
+		"{{range .DriftedResources}}• {{.}}\n{{end}}" +
+		"{{if .Result}}```\n{{.Result}}\n```{{else}}It failed to parse the result.{{end}}"
+
+	// DefaultSlackPlanAllTemplate is a default Slack message for terraform plan of multiple targets
+	DefaultSlackPlanAllTemplate = "{{if eq .ExitCode 1}}:x: Plan Failed{{else}}Plan Result{{end}} ({{len .Targets}} targets){{if .Vars.target}} ({{.Vars.target}}){{end}}{{if .Link}} <{{.Link}}|CI link>{{end}}\n" +
+		"{{range .Targets}}• {{.Target}}: {{template \"target_status\" .}}{{if .Result}} `{{.Result}}`{{end}}\n{{end}}"
+)
+
+// payload is the default JSON posted to the generic webhook
+type payload struct {
+	Command           string          `json:"command"`
+	Target            string          `json:"target,omitempty"`
+	ExitCode          int             `json:"exit_code"`
+	Result            string          `json:"result"`
+	Link              string          `json:"link,omitempty"`
+	HasChanges        bool            `json:"has_changes"`
+	HasDestroy        bool            `json:"has_destroy"`
+	HasError          bool            `json:"has_error"`
+	CreatedResources  []string        `json:"created_resources,omitempty"`
+	UpdatedResources  []string        `json:"updated_resources,omitempty"`
+	DeletedResources  []string        `json:"deleted_resources,omitempty"`
+	ReplacedResources []string        `json:"replaced_resources,omitempty"`
+	HasDrift          bool            `json:"has_drift,omitempty"`
+	DriftedResources  []string        `json:"drifted_resources,omitempty"`
+	Targets           []targetPayload `json:"targets,omitempty"`
+}
+
+type targetPayload struct {
+	Target     string `json:"target"`
+	ExitCode   int    `json:"exit_code"`
+	Result     string `json:"result"`
+	HasChanges bool   `json:"has_changes"`
+	HasDestroy bool   `json:"has_destroy"`
+	HasError   bool   `json:"has_error"`
+}
+
+// Notify posts the result of the command to the webhook
+func (c *Client) Notify(param notifier.ParamExec) (int, error) {
+	result := c.parse(param)
+	if c.skip(result) {
+		return result.ExitCode, nil
+	}
+
+	ct := c.commonTemplate(result)
+	ct.Stdout = param.Stdout
+	ct.Stderr = param.Stderr
+	ct.CombinedOutput = param.CombinedOutput
+
+	p := c.newPayload(result)
+	return notifier.PolicyExitCode(result.ExitCode, result), c.send(ct, p)
+}
+
+// NotifyAll posts the results of multiple targets to the webhook at once
+func (c *Client) NotifyAll(params []notifier.ParamExec) (int, error) {
+	targets := make([]terraform.TargetResult, len(params))
+	targetPayloads := make([]targetPayload, len(params))
+	combined := terraform.ParseResult{
+		HasNoChanges: len(params) != 0,
+	}
+	for i, param := range params {
+		result := c.parse(param)
+		targets[i] = terraform.TargetResult{
+			ParseResult:    result,
+			Target:         param.Target,
+			CombinedOutput: param.CombinedOutput,
+		}
+		targetPayloads[i] = targetPayload{
+			Target:     param.Target,
+			ExitCode:   result.ExitCode,
+			Result:     result.Result,
+			HasChanges: result.HasChanges,
+			HasDestroy: result.HasDestroy,
+			HasError:   result.HasPlanError || result.HasParseError,
+		}
+		if result.ExitCode > combined.ExitCode {
+			combined.ExitCode = result.ExitCode
+		}
+		combined.HasChanges = combined.HasChanges || result.HasChanges
+		combined.HasDestroy = combined.HasDestroy || result.HasDestroy
+		combined.HasPlanError = combined.HasPlanError || result.HasPlanError || result.HasParseError
+		combined.HasNoChanges = combined.HasNoChanges && result.HasNoChanges
+		combined.PolicyViolations = append(combined.PolicyViolations, result.PolicyViolations...)
+		if result.Tool != "" {
+			combined.Tool = result.Tool
+		}
+	}
+	if c.skip(combined) {
+		return combined.ExitCode, nil
+	}
+
+	ct := c.commonTemplate(combined)
+	ct.Targets = targets
+
+	p := c.newPayload(combined)
+	p.Targets = targetPayloads
+	return notifier.PolicyExitCode(combined.ExitCode, combined), c.send(ct, p)
+}
+
+// parse parses the output in the same way as the GitLab notifier so that both report the same result
+func (c *Client) parse(param notifier.ParamExec) terraform.ParseResult {
+	return notifier.Parse(param, notifier.ParseOption{
+		Parser:           c.Config.Parser,
+		DetailedExitCode: c.Config.DetailedExitCode,
+		ChangesExitCode:  c.Config.ChangesExitCode,
+		Policy:           c.Config.Policy,
+	})
+}
+
+// skip returns true if the notification isn't sent because the command succeeded and OnlyFailure is enabled
+func (c *Client) skip(result terraform.ParseResult) bool {
+	if !c.Config.OnlyFailure {
+		return false
+	}
+	if result.ExitCode == terraform.ExitFail || result.HasPlanError || result.HasParseError || len(result.PolicyViolations) != 0 {
+		return false
+	}
+	logrus.WithFields(logrus.Fields{
+		"program": "tfcmt",
+		"webhook": c.Config.Type,
+	}).Debug("skip the notification because the command succeeded")
+	return true
+}
+
+func (c *Client) commonTemplate(result terraform.ParseResult) terraform.CommonTemplate {
+	return terraform.CommonTemplate{
+		Result:                 result.Result,
+		ChangedResult:          result.ChangedResult,
+		ChangeOutsideTerraform: result.OutsideTerraform,
+		Warning:                result.Warning,
+		Link:                   c.Config.CI,
+		UseRawOutput:           true,
+		HasChanges:             result.HasChanges,
+		HasDestroy:             result.HasDestroy,
+		Vars:                   c.Config.Vars,
+		Templates:              c.Config.Templates,
+		ExitCode:               result.ExitCode,
+		CreatedResources:       result.CreatedResources,
+		UpdatedResources:       result.UpdatedResources,
+		DeletedResources:       result.DeletedResources,
+		ReplacedResources:      result.ReplacedResources,
+		MovedResources:         result.MovedResources,
+		ImportedResources:      result.ImportedResources,
+		ForgottenResources:     result.ForgottenResources,
+		ChangedOutputs:         result.ChangedOutputs,
+		Tool:                   terraform.ResolveToolName(c.Config.Tool, result),
+		Modules:                result.Modules,
+		DriftedResources:       result.DriftedResources,
+		AppliedResources:       result.AppliedResources,
+		PolicyViolations:       result.PolicyViolations,
+	}
+}
+
+func (c *Client) newPayload(result terraform.ParseResult) payload {
+	return payload{
+		Command:           c.Config.Command,
+		Target:            c.Config.Vars["target"],
+		ExitCode:          result.ExitCode,
+		Result:            result.Result,
+		Link:              c.Config.CI,
+		HasChanges:        result.HasChanges,
+		HasDestroy:        result.HasDestroy,
+		HasError:          result.HasPlanError || result.HasParseError,
+		CreatedResources:  result.CreatedResources,
+		UpdatedResources:  result.UpdatedResources,
+		DeletedResources:  result.DeletedResources,
+		ReplacedResources: result.ReplacedResources,
+		HasDrift:          result.HasDrift,
+		DriftedResources:  result.DriftedResources,
+	}
+}
+
+// send renders the body and posts it.
+// For Slack the rendered template is posted as the message.
+// For the generic webhook the rendered template is posted as is, or the payload is posted if no template is given.
+func (c *Client) send(ct terraform.CommonTemplate, p payload) error {
+	var body []byte
+	switch c.Config.Type {
+	case TypeSlack:
+		template := c.Config.Template
+		if template == nil {
+			template = &terraform.Template{Template: c.defaultSlackTemplate()}
+		}
+		template.SetValue(ct)
+		text, err := template.Execute()
+		if err != nil {
+			return fmt.Errorf("render the slack message: %w", err)
+		}
+		b, err := json.Marshal(map[string]string{"text": text})
+		if err != nil {
+			return fmt.Errorf("marshal the slack message as JSON: %w", err)
+		}
+		body = b
+	default:
+		if c.Config.Template != nil {
+			c.Config.Template.SetValue(ct)
+			s, err := c.Config.Template.Execute()
+			if err != nil {
+				return fmt.Errorf("render the webhook body: %w", err)
+			}
+			body = []byte(s)
+			break
+		}
+		b, err := json.Marshal(p)
+		if err != nil {
+			return fmt.Errorf("marshal the webhook body as JSON: %w", err)
+		}
+		body = b
+	}
+	if err := c.post(body); err != nil {
+		return fmt.Errorf("%s: %w", c.Config.Type, err)
+	}
+	return nil
+}
+
+func (c *Client) defaultSlackTemplate() string {
+	switch c.Config.Command {
+	case "apply":
+		return DefaultSlackApplyTemplate
+	case "plan-all":
+		return DefaultSlackPlanAllTemplate
+	case "drift":
+		return DefaultSlackDriftTemplate
+	default:
+		return DefaultSlackPlanTemplate
+	}
+}
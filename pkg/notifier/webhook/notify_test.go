@@ -0,0 +1,310 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hirosassa/tfcmt-gitlab/pkg/notifier"
+	"github.com/hirosassa/tfcmt-gitlab/pkg/terraform"
+)
+
+func TestClientNotify(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		name      string
+		config    Config
+		paramExec notifier.ParamExec
+		body      string
+		sent      bool
+		status    int
+		ok        bool
+	}{
+		{
+			name: "slack default apply message",
+			config: Config{
+				Type:    TypeSlack,
+				Command: "apply",
+				Parser:  terraform.NewApplyParser(),
+				CI:      "https://gitlab.example.com/jobs/1",
+				Vars:    map[string]string{"target": "prod"},
+			},
+			paramExec: notifier.ParamExec{
+				CombinedOutput: "Error: failed to apply",
+				ExitCode:       1,
+			},
+			body:   `{"text":":x: Apply Failed (prod) \u003chttps://gitlab.example.com/jobs/1|CI link\u003e\n` + "```\\nError: failed to apply\\n```" + `"}`,
+			sent:   true,
+			status: http.StatusOK,
+			ok:     true,
+		},
+		{
+			name: "generic webhook default payload",
+			config: Config{
+				Type:    TypeGeneric,
+				Command: "plan",
+				Parser:  terraform.NewPlanParser(),
+			},
+			paramExec: notifier.ParamExec{
+				CombinedOutput: "Plan: 1 to add, 0 to change, 1 to destroy.",
+			},
+			body:   `{"command":"plan","exit_code":0,"result":"Plan: 1 to add, 0 to change, 1 to destroy.","has_changes":true,"has_destroy":true,"has_error":false}`,
+			sent:   true,
+			status: http.StatusOK,
+			ok:     true,
+		},
+		{
+			name: "generic webhook with template",
+			config: Config{
+				Type:     TypeGeneric,
+				Command:  "plan",
+				Parser:   terraform.NewPlanParser(),
+				Template: &terraform.Template{Template: `{"summary": {{.Result | toJson}}}`},
+			},
+			paramExec: notifier.ParamExec{
+				CombinedOutput: "No changes. Infrastructure is up-to-date.",
+			},
+			body:   `{"summary": "No changes. Infrastructure is up-to-date."}`,
+			sent:   true,
+			status: http.StatusOK,
+			ok:     true,
+		},
+		{
+			name: "skip because the command succeeded",
+			config: Config{
+				Type:        TypeSlack,
+				Command:     "apply",
+				Parser:      terraform.NewApplyParser(),
+				OnlyFailure: true,
+			},
+			paramExec: notifier.ParamExec{
+				CombinedOutput: "Apply complete! Resources: 1 added, 0 changed, 0 destroyed.",
+			},
+			ok: true,
+		},
+		{
+			name: "webhook returns an error",
+			config: Config{
+				Type:    TypeSlack,
+				Command: "plan",
+				Parser:  terraform.NewPlanParser(),
+			},
+			paramExec: notifier.ParamExec{
+				CombinedOutput: "Plan: 1 to add, 0 to change, 0 to destroy.",
+			},
+			sent:   true,
+			status: http.StatusInternalServerError,
+		},
+	}
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+			sent := false
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				sent = true
+				b, err := io.ReadAll(r.Body)
+				if err != nil {
+					t.Error(err)
+				}
+				if testCase.body != "" {
+					if diff := cmp.Diff(testCase.body, string(b)); diff != "" {
+						t.Error(diff)
+					}
+				}
+				if ct := r.Header.Get("Content-Type"); ct != "application/json" {
+					t.Errorf("unexpected Content-Type: %s", ct)
+				}
+				w.WriteHeader(testCase.status)
+			}))
+			defer server.Close()
+
+			cfg := testCase.config
+			cfg.URL = server.URL
+			client, err := NewClient(cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = client.Notify(testCase.paramExec)
+			if (err == nil) != testCase.ok {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if sent != testCase.sent {
+				t.Errorf("wanted sent %v, got %v", testCase.sent, sent)
+			}
+		})
+	}
+}
+
+func TestClientNotifyAll(t *testing.T) {
+	t.Parallel()
+	var got payload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClient(Config{
+		Type:    TypeGeneric,
+		URL:     server.URL,
+		Command: "plan-all",
+		Parser:  terraform.NewPlanParser(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	exitCode, err := client.NotifyAll([]notifier.ParamExec{
+		{Target: "dev", CombinedOutput: "Plan: 1 to add, 0 to change, 0 to destroy."},
+		{Target: "prod", CombinedOutput: "Error: Invalid reference", ExitCode: 1},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exitCode != 1 {
+		t.Errorf("wanted exit code 1, got %d", exitCode)
+	}
+	exp := payload{
+		Command:    "plan-all",
+		ExitCode:   1,
+		HasChanges: true,
+		HasError:   true,
+		Targets: []targetPayload{
+			{Target: "dev", Result: "Plan: 1 to add, 0 to change, 0 to destroy.", HasChanges: true},
+			{Target: "prod", ExitCode: 1, Result: "Error: Invalid reference", HasError: true},
+		},
+	}
+	if diff := cmp.Diff(exp, got); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestClientNotifyParse(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		name      string
+		config    Config
+		paramExec notifier.ParamExec
+		exitCode  int
+		exp       payload
+	}{
+		{
+			name: "detailed exit code",
+			config: Config{
+				Parser:           terraform.NewPlanParser(),
+				DetailedExitCode: true,
+			},
+			paramExec: notifier.ParamExec{
+				CombinedOutput: "Plan: 1 to add, 0 to change, 0 to destroy.",
+				ExitCode:       terraform.ExitChanges,
+			},
+			exitCode: 0,
+			exp: payload{
+				Command:    "plan",
+				Result:     "Plan: 1 to add, 0 to change, 0 to destroy.",
+				HasChanges: true,
+			},
+		},
+		{
+			name: "policy violation",
+			config: Config{
+				Parser: terraform.NewPlanParser(),
+				Policy: []terraform.PolicyRule{{Name: "no-destroy", Actions: []string{terraform.ActionDelete}}},
+			},
+			paramExec: notifier.ParamExec{
+				CombinedOutput: `Terraform will perform the following actions:
+
+  # null_resource.foo will be destroyed
+  - resource "null_resource" "foo" {}
+
+Plan: 0 to add, 0 to change, 1 to destroy.`,
+			},
+			exitCode: terraform.ExitFail,
+			exp: payload{
+				Command:          "plan",
+				Result:           "Plan: 0 to add, 0 to change, 1 to destroy.",
+				HasChanges:       true,
+				HasDestroy:       true,
+				DeletedResources: []string{"null_resource.foo"},
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+			var got payload
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+					t.Error(err)
+				}
+			}))
+			defer server.Close()
+
+			cfg := testCase.config
+			cfg.Type = TypeGeneric
+			cfg.URL = server.URL
+			cfg.Command = "plan"
+			client, err := NewClient(cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			exitCode, err := client.Notify(testCase.paramExec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if exitCode != testCase.exitCode {
+				t.Errorf("wanted exit code %d, got %d", testCase.exitCode, exitCode)
+			}
+			if diff := cmp.Diff(testCase.exp, got); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestClientWithContext(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("the request should be canceled")
+	}))
+	defer server.Close()
+
+	client, err := NewClient(Config{
+		Type:    TypeGeneric,
+		URL:     server.URL,
+		Command: "plan",
+		Parser:  terraform.NewPlanParser(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := client.WithContext(ctx).Notify(notifier.ParamExec{CombinedOutput: "Plan: 1 to add, 0 to change, 0 to destroy."}); !errors.Is(err, context.Canceled) {
+		t.Errorf("wanted context.Canceled, got %v", err)
+	}
+}
+
+func TestNewClient(t *testing.T) { //nolint:paralleltest
+	t.Setenv("TFCMT_TEST_WEBHOOK_URL", "https://hooks.example.com/xxx")
+	client, err := NewClient(Config{Type: TypeSlack, URL: "$TFCMT_TEST_WEBHOOK_URL"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.Config.URL != "https://hooks.example.com/xxx" {
+		t.Errorf("the url should be read from the environment variable: %s", client.Config.URL)
+	}
+	if _, err := NewClient(Config{Type: "teams", URL: "https://example.com"}); err == nil {
+		t.Error("unknown type should be rejected")
+	}
+	if _, err := NewClient(Config{Type: TypeGeneric, URL: "$TFCMT_TEST_UNDEFINED"}); err == nil {
+		t.Error("empty url should be rejected")
+	}
+}
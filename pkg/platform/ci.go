@@ -29,6 +29,12 @@ func complementWithCIEnv(ci *config.CI) error {
 		ci.Project = os.Getenv("CI_PROJECT_NAME")
 	}
 
+	if ci.SHA == "" {
+		// In merged results pipelines, CI_COMMIT_SHA is the merge commit which isn't in the merge request,
+		// so the head of the source branch is preferred
+		ci.SHA = os.Getenv("CI_MERGE_REQUEST_SOURCE_BRANCH_SHA")
+	}
+
 	if ci.SHA == "" {
 		ci.SHA = os.Getenv("CI_COMMIT_SHA")
 	}
@@ -44,6 +50,18 @@ func complementWithCIEnv(ci *config.CI) error {
 	if ci.Link == "" {
 		ci.Link = os.Getenv("CI_JOB_URL")
 	}
+
+	if ci.PipelineID == "" {
+		ci.PipelineID = os.Getenv("CI_PIPELINE_ID")
+	}
+
+	if ci.PipelineSHA == "" {
+		ci.PipelineSHA = os.Getenv("CI_COMMIT_SHA")
+	}
+
+	if ci.JobName == "" {
+		ci.JobName = os.Getenv("CI_JOB_NAME")
+	}
 	return nil
 }
 
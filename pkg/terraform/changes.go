@@ -0,0 +1,180 @@
+package terraform
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+)
+
+// maxSummaryChangesSize is the maximum size of the addresses and the actions in the summary of the planned changes.
+// The summary is embedded in the metadata of the comment, so it has to fit in the margin reserved in the split comments
+const maxSummaryChangesSize = 8000
+
+// ChangeSummary is the summary of the changes of the resources.
+// It's small enough to be embedded in the comment even if the plan has many changes
+type ChangeSummary struct {
+	// Hash is the SHA-256 of the sorted pairs of the address and the action
+	Hash string `json:"hash"`
+	// Counts are the numbers of the changed resources by the action
+	Counts map[string]int `json:"counts,omitempty"`
+	// Changes are the actions keyed by the address.
+	// They're omitted if the plan has too many changes, and then only the hash and the counts are compared
+	Changes map[string]string `json:"changes,omitempty"`
+}
+
+// hasAddresses returns true if the summary has the addresses of all the changes
+func (s ChangeSummary) hasAddresses() bool {
+	return len(s.Changes) != 0 || len(s.Counts) == 0
+}
+
+// ActionCount is the numbers of the planned and the applied changes of an action
+type ActionCount struct {
+	Action  string
+	Planned int
+	Applied int
+}
+
+// ResourceChange is the change of a resource
+type ResourceChange struct {
+	Address string
+	Action  string
+}
+
+// ActionDiff is the resource whose applied action differs from the planned action
+type ActionDiff struct {
+	Address string
+	Planned string
+	Applied string
+}
+
+// ChangeDiffs are the differences between the planned changes and the applied changes
+type ChangeDiffs struct {
+	// Added are the changes which were applied but not planned
+	Added []ResourceChange
+	// Missing are the changes which were planned but not applied
+	Missing []ResourceChange
+	// Changed are the resources whose applied actions differ from the planned actions
+	Changed []ActionDiff
+	// Counts are the numbers of the changes by the action.
+	// They're set instead of the addresses only if the addresses of the planned changes aren't embedded
+	Counts []ActionCount
+}
+
+// summaryActions are the actions in the order of the comparison
+var summaryActions = []string{ActionImport, ActionCreate, ActionUpdate, ActionDelete, ActionReplace}
+
+// PlannedChanges returns the summary of the changes in the plan result.
+// The imported resources are ActionImport even if they're updated too because apply reports them as imports.
+// The moved and forgotten resources aren't included because apply doesn't report them.
+func PlannedChanges(result ParseResult) ChangeSummary {
+	changes := map[string]string{}
+	for _, c := range []struct {
+		action    string
+		addresses []string
+	}{
+		{action: ActionImport, addresses: result.ImportedResources},
+		{action: ActionCreate, addresses: result.CreatedResources},
+		{action: ActionUpdate, addresses: result.UpdatedResources},
+		{action: ActionDelete, addresses: result.DeletedResources},
+		{action: ActionReplace, addresses: result.ReplacedResources},
+	} {
+		for _, address := range c.addresses {
+			if _, ok := changes[address]; !ok {
+				changes[address] = c.action
+			}
+		}
+	}
+	summary := summarizeChanges(changes)
+	size := 0
+	for address, action := range summary.Changes {
+		size += len(address) + len(action) + 6 //nolint:gomnd // the quotes, the colon and the comma in JSON
+	}
+	if size > maxSummaryChangesSize {
+		summary.Changes = nil
+	}
+	return summary
+}
+
+// AppliedChanges returns the summary of the changes in the apply result.
+// The failed operations are treated as not applied.
+func AppliedChanges(resources []AppliedResource) ChangeSummary {
+	changes := make(map[string]string, len(resources))
+	for _, r := range resources {
+		if !r.Failed {
+			changes[r.Address] = r.Action
+		}
+	}
+	return summarizeChanges(changes)
+}
+
+func sortedAddresses(changes map[string]string) []string {
+	addresses := make([]string, 0, len(changes))
+	for address := range changes {
+		addresses = append(addresses, address)
+	}
+	sort.Strings(addresses)
+	return addresses
+}
+
+func summarizeChanges(changes map[string]string) ChangeSummary {
+	addresses := sortedAddresses(changes)
+	h := sha256.New()
+	summary := ChangeSummary{}
+	for _, address := range addresses {
+		action := changes[address]
+		h.Write([]byte(address + "\t" + action + "\n"))
+		if summary.Counts == nil {
+			summary.Counts = map[string]int{}
+			summary.Changes = map[string]string{}
+		}
+		summary.Counts[action]++
+		summary.Changes[address] = action
+	}
+	summary.Hash = hex.EncodeToString(h.Sum(nil))
+	return summary
+}
+
+// CompareChanges returns the differences if the applied changes differ from the planned changes.
+// It returns nil if they match.
+// The added, missing and changed resources are listed by the address,
+// or only the numbers of the changes by the action are compared if the addresses of the planned changes aren't embedded.
+func CompareChanges(planned, applied ChangeSummary) *ChangeDiffs {
+	if planned.Hash == applied.Hash {
+		return nil
+	}
+	if !planned.hasAddresses() || !applied.hasAddresses() {
+		return &ChangeDiffs{Counts: compareCounts(planned, applied)}
+	}
+	diffs := &ChangeDiffs{}
+	for _, address := range sortedAddresses(applied.Changes) {
+		action := applied.Changes[address]
+		p, ok := planned.Changes[address]
+		switch {
+		case !ok:
+			diffs.Added = append(diffs.Added, ResourceChange{Address: address, Action: action})
+		case p != action:
+			diffs.Changed = append(diffs.Changed, ActionDiff{Address: address, Planned: p, Applied: action})
+		}
+	}
+	for _, address := range sortedAddresses(planned.Changes) {
+		if _, ok := applied.Changes[address]; !ok {
+			diffs.Missing = append(diffs.Missing, ResourceChange{Address: address, Action: planned.Changes[address]})
+		}
+	}
+	return diffs
+}
+
+func compareCounts(planned, applied ChangeSummary) []ActionCount {
+	counts := []ActionCount{}
+	for _, action := range summaryActions {
+		count := ActionCount{
+			Action:  action,
+			Planned: planned.Counts[action],
+			Applied: applied.Counts[action],
+		}
+		if count.Planned != 0 || count.Applied != 0 {
+			counts = append(counts, count)
+		}
+	}
+	return counts
+}
@@ -0,0 +1,155 @@
+package terraform
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestPlannedChanges(t *testing.T) {
+	t.Parallel()
+	result := ParseResult{
+		CreatedResources:  []string{"null_resource.foo"},
+		UpdatedResources:  []string{"null_resource.bar", "null_resource.imported"},
+		DeletedResources:  []string{"null_resource.baz"},
+		ReplacedResources: []string{"null_resource.qux"},
+		ImportedResources: []string{"null_resource.imported"},
+		MovedResources:    []MovedResource{{From: "null_resource.a", To: "null_resource.b"}},
+	}
+	planned := PlannedChanges(result)
+	exp := map[string]int{
+		ActionCreate:  1,
+		ActionUpdate:  1,
+		ActionDelete:  1,
+		ActionReplace: 1,
+		ActionImport:  1,
+	}
+	if diff := cmp.Diff(exp, planned.Counts); diff != "" {
+		t.Error(diff)
+	}
+	applied := AppliedChanges([]AppliedResource{
+		{Address: "null_resource.qux", Action: ActionReplace},
+		{Address: "null_resource.imported", Action: ActionImport},
+		{Address: "null_resource.baz", Action: ActionDelete},
+		{Address: "null_resource.bar", Action: ActionUpdate},
+		{Address: "null_resource.foo", Action: ActionCreate},
+	})
+	if planned.Hash != applied.Hash {
+		t.Errorf("the hash shouldn't depend on the order of the resources: %s, %s", planned.Hash, applied.Hash)
+	}
+	if diff := cmp.Diff(applied.Changes, planned.Changes); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestPlannedChangesTooMany(t *testing.T) {
+	t.Parallel()
+	addresses := make([]string, 1000)
+	for i := range addresses {
+		addresses[i] = fmt.Sprintf("null_resource.foo[%d]", i)
+	}
+	planned := PlannedChanges(ParseResult{CreatedResources: addresses})
+	if planned.Changes != nil {
+		t.Errorf("the addresses shouldn't be embedded if the plan has too many changes: %d", len(planned.Changes))
+	}
+	if diff := cmp.Diff(map[string]int{ActionCreate: 1000}, planned.Counts); diff != "" {
+		t.Error(diff)
+	}
+	exp := &ChangeDiffs{Counts: []ActionCount{{Action: ActionCreate, Planned: 1000}}}
+	if diff := cmp.Diff(exp, CompareChanges(planned, AppliedChanges(nil))); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestCompareChanges(t *testing.T) {
+	t.Parallel()
+	planned := PlannedChanges(ParseResult{
+		CreatedResources: []string{"null_resource.foo"},
+		UpdatedResources: []string{"null_resource.bar"},
+		DeletedResources: []string{"null_resource.baz"},
+	})
+	// the plan comments which have too many changes or were posted before the addresses were embedded
+	withoutAddresses := planned
+	withoutAddresses.Changes = nil
+	testCases := []struct {
+		name    string
+		planned ChangeSummary
+		applied []AppliedResource
+		exp     *ChangeDiffs
+	}{
+		{
+			name:    "same changes",
+			planned: planned,
+			applied: []AppliedResource{
+				{Address: "null_resource.bar", Action: ActionUpdate},
+				{Address: "null_resource.foo", Action: ActionCreate},
+				{Address: "null_resource.baz", Action: ActionDelete},
+			},
+		},
+		{
+			name:    "different changes",
+			planned: planned,
+			applied: []AppliedResource{
+				{Address: "null_resource.foo", Action: ActionCreate},
+				{Address: "null_resource.bar", Action: ActionReplace},
+				{Address: "null_resource.baz", Action: ActionDelete, Failed: true},
+				{Address: "null_resource.qux", Action: ActionCreate},
+			},
+			exp: &ChangeDiffs{
+				Added:   []ResourceChange{{Address: "null_resource.qux", Action: ActionCreate}},
+				Missing: []ResourceChange{{Address: "null_resource.baz", Action: ActionDelete}},
+				Changed: []ActionDiff{{Address: "null_resource.bar", Planned: ActionUpdate, Applied: ActionReplace}},
+			},
+		},
+		{
+			name:    "different resources with the same numbers",
+			planned: planned,
+			applied: []AppliedResource{
+				{Address: "null_resource.qux", Action: ActionCreate},
+				{Address: "null_resource.bar", Action: ActionUpdate},
+				{Address: "null_resource.baz", Action: ActionDelete},
+			},
+			exp: &ChangeDiffs{
+				Added:   []ResourceChange{{Address: "null_resource.qux", Action: ActionCreate}},
+				Missing: []ResourceChange{{Address: "null_resource.foo", Action: ActionCreate}},
+			},
+		},
+		{
+			name:    "the numbers are compared without the addresses",
+			planned: withoutAddresses,
+			applied: []AppliedResource{
+				{Address: "null_resource.foo", Action: ActionCreate},
+				{Address: "null_resource.bar", Action: ActionReplace},
+				{Address: "null_resource.qux", Action: ActionCreate},
+			},
+			exp: &ChangeDiffs{
+				Counts: []ActionCount{
+					{Action: ActionCreate, Planned: 1, Applied: 2},
+					{Action: ActionUpdate, Planned: 1},
+					{Action: ActionDelete, Planned: 1},
+					{Action: ActionReplace, Applied: 1},
+				},
+			},
+		},
+		{
+			name:    "nothing is planned",
+			planned: PlannedChanges(ParseResult{}),
+			applied: []AppliedResource{
+				{Address: "null_resource.foo", Action: ActionCreate},
+			},
+			exp: &ChangeDiffs{
+				Added: []ResourceChange{{Address: "null_resource.foo", Action: ActionCreate}},
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+			if diff := cmp.Diff(testCase.exp, CompareChanges(testCase.planned, AppliedChanges(testCase.applied))); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package terraform
+
+import (
+	"regexp"
+	"strings"
+)
+
+// DriftParser is a parser for the output of `terraform plan -refresh-only` and `terraform plan` to detect the drift.
+// The drift is the changes outside of the tool and the changes which the plan would make.
+type DriftParser struct {
+	Plan *PlanParser
+	// Drifted matches the header line of the resource which has changed outside of the tool
+	Drifted *regexp.Regexp
+}
+
+// NewDriftParser is DriftParser initializer
+func NewDriftParser() *DriftParser {
+	return &DriftParser{
+		Plan:    NewPlanParser(),
+		Drifted: regexp.MustCompile(`^ *# (.*) has (?:changed|been deleted)$`),
+	}
+}
+
+// Parse returns ParseResult related with the drift.
+// A refresh-only plan doesn't output "Plan: " when objects have changed outside of the tool,
+// so the note of the changes is used as the result.
+func (p *DriftParser) Parse(body string) ParseResult {
+	lines := strings.Split(body, "\n")
+	var note string
+	var drifted []string
+	startOutside := -1
+	endOutside := -1
+	for i, line := range lines {
+		if p.Plan.OutsideChanges.MatchString(line) {
+			note = strings.TrimPrefix(line, "Note: ")
+			startOutside = i + 1
+			continue
+		}
+		if startOutside == -1 || endOutside != -1 {
+			continue
+		}
+		if isEndOfOutsideChanges(line) {
+			endOutside = i + 1
+			continue
+		}
+		if rsc := extractResource(p.Drifted, line); rsc != "" {
+			drifted = append(drifted, rsc)
+		}
+	}
+
+	result := p.Plan.Parse(body)
+	if note == "" {
+		result.HasDrift = result.HasChanges && !result.HasParseError
+		return result
+	}
+	if result.HasParseError {
+		if endOutside == -1 {
+			endOutside = len(lines)
+		}
+		result = ParseResult{
+			Result:           note,
+			OutsideTerraform: strings.Join(lines[startOutside:endOutside], "\n"),
+			ExitCode:         ExitPass,
+			Tool:             detectToolFromOutput(body),
+		}
+	}
+	result.HasDrift = !result.HasPlanError
+	result.DriftedResources = drifted
+	return result
+}
@@ -0,0 +1,126 @@
+package terraform
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+const refreshOnlyPlanDrift = `null_resource.foo: Refreshing state... [id=1]
+
+Note: Objects have changed outside of Terraform
+
+Terraform detected the following changes made outside of Terraform since the
+last "terraform apply" which may have affected this plan:
+
+  # null_resource.foo has been deleted
+  - resource "null_resource" "foo" {
+      - id = "1" -> null
+    }
+
+  # aws_s3_bucket.bar has changed
+  ~ resource "aws_s3_bucket" "bar" {
+      ~ tags = {
+          + "owner" = "someone"
+        }
+    }
+
+This is a refresh-only plan, so Terraform will not take any actions to undo
+these. If you were expecting these changes then you can apply this plan to
+record the updated values in the Terraform state without changing any remote
+objects.
+`
+
+func TestDriftParserParse(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		name   string
+		body   string
+		result ParseResult
+	}{
+		{
+			name: "refresh-only plan with drift",
+			body: refreshOnlyPlanDrift,
+			result: ParseResult{
+				Result: "Objects have changed outside of Terraform",
+				OutsideTerraform: `
+Terraform detected the following changes made outside of Terraform since the
+last "terraform apply" which may have affected this plan:
+
+  # null_resource.foo has been deleted
+  - resource "null_resource" "foo" {
+      - id = "1" -> null
+    }
+
+  # aws_s3_bucket.bar has changed
+  ~ resource "aws_s3_bucket" "bar" {
+      ~ tags = {
+          + "owner" = "someone"
+        }
+    }
+
+This is a refresh-only plan, so Terraform will not take any actions to undo`,
+				ExitCode:         ExitPass,
+				Tool:             ToolTerraform,
+				HasDrift:         true,
+				DriftedResources: []string{"null_resource.foo", "aws_s3_bucket.bar"},
+			},
+		},
+		{
+			name: "refresh-only plan without drift",
+			body: `No changes. Your infrastructure still matches the configuration.
+
+Terraform has checked that the real remote objects still match the result of
+your most recent changes, and found no differences.`,
+			result: ParseResult{
+				Result:       "No changes. Your infrastructure still matches the configuration.",
+				HasNoChanges: true,
+				ExitCode:     ExitPass,
+				Tool:         ToolTerraform,
+			},
+		},
+		{
+			name: "plan with changes",
+			body: `Terraform will perform the following actions:
+
+  # null_resource.foo will be created
+  + resource "null_resource" "foo" {}
+
+Plan: 1 to add, 0 to change, 0 to destroy.`,
+			result: ParseResult{
+				Result: "Plan: 1 to add, 0 to change, 0 to destroy.",
+				ChangedResult: `
+  # null_resource.foo will be created
+  + resource "null_resource" "foo" {}
+
+Plan: 1 to add, 0 to change, 0 to destroy.`,
+				HasAddOrUpdateOnly: true,
+				HasChanges:         true,
+				ExitCode:           ExitPass,
+				Tool:               ToolTerraform,
+				CreatedResources:   []string{"null_resource.foo"},
+				HasDrift:           true,
+			},
+		},
+		{
+			name: "plan error",
+			body: `Error: Invalid reference`,
+			result: ParseResult{
+				Result:       "Error: Invalid reference",
+				HasPlanError: true,
+				ExitCode:     ExitFail,
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+			result := NewDriftParser().Parse(testCase.body)
+			if diff := cmp.Diff(testCase.result, result, cmpopts.IgnoreFields(ParseResult{}, "Error")); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,217 @@
+package terraform
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// JSONPlanParser is a parser for the JSON representation of terraform plan (`terraform show -json <plan file>`)
+type JSONPlanParser struct {
+	// Fallback parses the body when it isn't a JSON plan, e.g. when terraform plan failed and no plan file was created
+	Fallback Parser
+}
+
+// NewJSONPlanParser is JSONPlanParser initializer
+func NewJSONPlanParser() *JSONPlanParser {
+	return &JSONPlanParser{
+		Fallback: NewPlanParser(),
+	}
+}
+
+// https://developer.hashicorp.com/terraform/internals/json-format#plan-representation
+type jsonPlan struct {
+	FormatVersion   string                `json:"format_version"`
+	ResourceChanges []jsonResourceChange  `json:"resource_changes"`
+	ResourceDrift   []jsonResourceChange  `json:"resource_drift"`
+	OutputChanges   map[string]jsonChange `json:"output_changes"`
+	Diagnostics     []jsonDiagnostic      `json:"diagnostics"`
+	Errored         bool                  `json:"errored"`
+}
+
+type jsonResourceChange struct {
+	Address         string     `json:"address"`
+	PreviousAddress string     `json:"previous_address"`
+	Mode            string     `json:"mode"`
+	Change          jsonChange `json:"change"`
+}
+
+type jsonChange struct {
+	Actions   []string       `json:"actions"`
+	Importing *jsonImporting `json:"importing"`
+}
+
+type jsonImporting struct {
+	ID string `json:"id"`
+}
+
+type jsonDiagnostic struct {
+	Severity string `json:"severity"`
+	Summary  string `json:"summary"`
+	Detail   string `json:"detail"`
+	Address  string `json:"address"`
+}
+
+const (
+	jsonActionNoOp   = "no-op"
+	jsonActionCreate = "create"
+	jsonActionRead   = "read"
+	jsonActionUpdate = "update"
+	jsonActionDelete = "delete"
+	jsonActionForget = "forget"
+)
+
+// jsonAction normalizes the list of actions into a single action.
+// ["delete", "create"] and ["create", "delete"] are treated as "replace".
+func jsonAction(actions []string) string {
+	if len(actions) == 2 { //nolint:gomnd
+		return ActionReplace
+	}
+	if len(actions) == 1 {
+		return actions[0]
+	}
+	return jsonActionNoOp
+}
+
+// Parse returns ParseResult related with the JSON representation of terraform plan
+func (p *JSONPlanParser) Parse(body string) ParseResult { //nolint:cyclop
+	if !strings.HasPrefix(strings.TrimSpace(body), "{") {
+		if p.Fallback != nil {
+			return p.Fallback.Parse(body)
+		}
+		return ParseResult{
+			HasParseError: true,
+			ExitCode:      ExitFail,
+			Error:         errors.New("cannot parse plan result"),
+		}
+	}
+
+	plan := jsonPlan{}
+	if err := json.Unmarshal([]byte(body), &plan); err != nil {
+		return ParseResult{
+			HasParseError: true,
+			ExitCode:      ExitFail,
+			Error:         fmt.Errorf("parse the plan as JSON: %w", err),
+		}
+	}
+
+	var createdResources, updatedResources, deletedResources, replacedResources, importedResources, forgottenResources []string
+	var movedResources []MovedResource
+	var changeLines []string
+	for _, rc := range plan.ResourceChanges {
+		if rc.Mode == "data" {
+			continue
+		}
+		if rc.PreviousAddress != "" && rc.PreviousAddress != rc.Address {
+			movedResources = append(movedResources, MovedResource{From: rc.PreviousAddress, To: rc.Address})
+			changeLines = append(changeLines, "  # "+rc.PreviousAddress+" has moved to "+rc.Address)
+		}
+		if rc.Change.Importing != nil {
+			importedResources = append(importedResources, rc.Address)
+			changeLines = append(changeLines, "  # "+rc.Address+" will be imported")
+		}
+		switch jsonAction(rc.Change.Actions) {
+		case jsonActionCreate:
+			createdResources = append(createdResources, rc.Address)
+			changeLines = append(changeLines, "  # "+rc.Address+" will be created")
+		case jsonActionUpdate:
+			updatedResources = append(updatedResources, rc.Address)
+			changeLines = append(changeLines, "  # "+rc.Address+" will be updated in-place")
+		case jsonActionDelete:
+			deletedResources = append(deletedResources, rc.Address)
+			changeLines = append(changeLines, "  # "+rc.Address+" will be destroyed")
+		case ActionReplace:
+			replacedResources = append(replacedResources, rc.Address)
+			changeLines = append(changeLines, "  # "+rc.Address+" must be replaced")
+		case jsonActionForget:
+			forgottenResources = append(forgottenResources, rc.Address)
+			changeLines = append(changeLines, "  # "+rc.Address+" will be removed from the Terraform state but will not be destroyed")
+		}
+	}
+
+	var driftLines []string
+	for _, rc := range plan.ResourceDrift {
+		switch jsonAction(rc.Change.Actions) {
+		case jsonActionDelete:
+			driftLines = append(driftLines, "  # "+rc.Address+" has been deleted")
+		case jsonActionNoOp, jsonActionRead:
+		default:
+			driftLines = append(driftLines, "  # "+rc.Address+" has changed")
+		}
+	}
+
+	var outputNames []string
+	for name, change := range plan.OutputChanges {
+		if a := jsonAction(change.Actions); a == jsonActionNoOp || a == jsonActionRead {
+			continue
+		}
+		outputNames = append(outputNames, name)
+	}
+	sort.Strings(outputNames)
+
+	var warnings, errs []string
+	for _, diag := range plan.Diagnostics {
+		switch diag.Severity {
+		case "warning":
+			warnings = append(warnings, strings.TrimSpace("Warning: "+diag.Summary+"\n\n"+diag.Detail))
+		case "error":
+			errs = append(errs, strings.TrimSpace("Error: "+diag.Summary+"\n\n"+diag.Detail))
+		}
+	}
+
+	add := len(createdResources) + len(replacedResources)
+	change := len(updatedResources)
+	destroy := len(deletedResources) + len(replacedResources)
+
+	hasPlanError := plan.Errored || len(errs) != 0
+	hasNoChanges := !hasPlanError && add == 0 && change == 0 && destroy == 0 && len(outputNames) == 0 &&
+		len(movedResources) == 0 && len(importedResources) == 0 && len(forgottenResources) == 0
+	hasDestroy := !hasPlanError && destroy != 0
+
+	exitCode := ExitPass
+	var result string
+	switch {
+	case hasPlanError:
+		exitCode = ExitFail
+		result = strings.Join(errs, "\n\n")
+		if result == "" {
+			result = "Error: the plan is errored"
+		}
+	case hasNoChanges:
+		result = "No changes. Your infrastructure matches the configuration."
+	default:
+		result = "Plan: "
+		if len(importedResources) != 0 {
+			result += fmt.Sprintf("%d to import, ", len(importedResources))
+		}
+		result += fmt.Sprintf("%d to add, %d to change, %d to destroy", add, change, destroy)
+		if len(forgottenResources) != 0 {
+			result += fmt.Sprintf(", %d to forget", len(forgottenResources))
+		}
+		result += "."
+	}
+
+	return ParseResult{
+		Result:             result,
+		ChangedResult:      strings.Join(changeLines, "\n"),
+		OutsideTerraform:   strings.Join(driftLines, "\n"),
+		Warning:            strings.Join(warnings, "\n\n"),
+		HasAddOrUpdateOnly: !hasNoChanges && !hasDestroy && !hasPlanError,
+		HasChanges:         !hasNoChanges && !hasPlanError,
+		HasDestroy:         hasDestroy,
+		HasNoChanges:       hasNoChanges,
+		HasPlanError:       hasPlanError,
+		ExitCode:           exitCode,
+		Error:              nil,
+		CreatedResources:   createdResources,
+		UpdatedResources:   updatedResources,
+		DeletedResources:   deletedResources,
+		ReplacedResources:  replacedResources,
+		MovedResources:     movedResources,
+		ImportedResources:  importedResources,
+		ForgottenResources: forgottenResources,
+		ChangedOutputs:     outputNames,
+	}
+}
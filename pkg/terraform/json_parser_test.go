@@ -0,0 +1,206 @@
+package terraform
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+const jsonPlanWithChanges = `{
+  "format_version": "1.2",
+  "terraform_version": "1.6.0",
+  "resource_drift": [
+    {
+      "address": "aws_s3_bucket.logs",
+      "mode": "managed",
+      "change": {"actions": ["update"]}
+    },
+    {
+      "address": "aws_s3_bucket.tmp",
+      "mode": "managed",
+      "change": {"actions": ["delete"]}
+    }
+  ],
+  "resource_changes": [
+    {
+      "address": "data.aws_caller_identity.current",
+      "mode": "data",
+      "change": {"actions": ["read"]}
+    },
+    {
+      "address": "aws_instance.web",
+      "mode": "managed",
+      "change": {"actions": ["create"]}
+    },
+    {
+      "address": "aws_instance.api",
+      "mode": "managed",
+      "change": {"actions": ["update"]}
+    },
+    {
+      "address": "aws_instance.old",
+      "mode": "managed",
+      "change": {"actions": ["delete"]}
+    },
+    {
+      "address": "aws_instance.db",
+      "mode": "managed",
+      "change": {"actions": ["delete", "create"]}
+    },
+    {
+      "address": "aws_instance.unchanged",
+      "mode": "managed",
+      "change": {"actions": ["no-op"]}
+    }
+  ],
+  "output_changes": {
+    "stable": {"actions": ["no-op"]},
+    "web_ip": {"actions": ["create"]}
+  },
+  "diagnostics": [
+    {"severity": "warning", "summary": "Deprecated attribute", "detail": "Use foo instead."}
+  ]
+}`
+
+const jsonPlanNoChanges = `{
+  "format_version": "1.2",
+  "terraform_version": "1.6.0",
+  "resource_changes": [
+    {
+      "address": "aws_instance.web",
+      "mode": "managed",
+      "change": {"actions": ["no-op"]}
+    }
+  ]
+}`
+
+const jsonPlanWithMovedImportedAndForgotten = `{
+  "format_version": "1.2",
+  "terraform_version": "1.7.0",
+  "resource_changes": [
+    {
+      "address": "aws_instance.new",
+      "previous_address": "aws_instance.old",
+      "mode": "managed",
+      "change": {"actions": ["no-op"]}
+    },
+    {
+      "address": "aws_s3_bucket.imported",
+      "mode": "managed",
+      "change": {"actions": ["no-op"], "importing": {"id": "imported"}}
+    },
+    {
+      "address": "aws_iam_user.legacy",
+      "mode": "managed",
+      "change": {"actions": ["forget"]}
+    }
+  ]
+}`
+
+const jsonPlanErrored = `{
+  "format_version": "1.2",
+  "terraform_version": "1.6.0",
+  "errored": true,
+  "diagnostics": [
+    {"severity": "error", "summary": "Invalid reference", "detail": "A reference to a resource type must be followed by at least one attribute access."}
+  ]
+}`
+
+func TestJSONPlanParserParse(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		name   string
+		body   string
+		result ParseResult
+	}{
+		{
+			name: "plan has changes",
+			body: jsonPlanWithChanges,
+			result: ParseResult{
+				Result: "Plan: 2 to add, 1 to change, 2 to destroy.",
+				ChangedResult: `  # aws_instance.web will be created
+  # aws_instance.api will be updated in-place
+  # aws_instance.old will be destroyed
+  # aws_instance.db must be replaced`,
+				OutsideTerraform: `  # aws_s3_bucket.logs has changed
+  # aws_s3_bucket.tmp has been deleted`,
+				Warning:           "Warning: Deprecated attribute\n\nUse foo instead.",
+				HasChanges:        true,
+				HasDestroy:        true,
+				ExitCode:          0,
+				CreatedResources:  []string{"aws_instance.web"},
+				UpdatedResources:  []string{"aws_instance.api"},
+				DeletedResources:  []string{"aws_instance.old"},
+				ReplacedResources: []string{"aws_instance.db"},
+				ChangedOutputs:    []string{"web_ip"},
+			},
+		},
+		{
+			name: "plan has no changes",
+			body: jsonPlanNoChanges,
+			result: ParseResult{
+				Result:       "No changes. Your infrastructure matches the configuration.",
+				HasNoChanges: true,
+				ExitCode:     0,
+			},
+		},
+		{
+			name: "plan has moved, imported and forgotten resources",
+			body: jsonPlanWithMovedImportedAndForgotten,
+			result: ParseResult{
+				Result: "Plan: 1 to import, 0 to add, 0 to change, 0 to destroy, 1 to forget.",
+				ChangedResult: `  # aws_instance.old has moved to aws_instance.new
+  # aws_s3_bucket.imported will be imported
+  # aws_iam_user.legacy will be removed from the Terraform state but will not be destroyed`,
+				HasAddOrUpdateOnly: true,
+				HasChanges:         true,
+				ExitCode:           0,
+				MovedResources:     []MovedResource{{From: "aws_instance.old", To: "aws_instance.new"}},
+				ImportedResources:  []string{"aws_s3_bucket.imported"},
+				ForgottenResources: []string{"aws_iam_user.legacy"},
+			},
+		},
+		{
+			name: "plan is errored",
+			body: jsonPlanErrored,
+			result: ParseResult{
+				Result:       "Error: Invalid reference\n\nA reference to a resource type must be followed by at least one attribute access.",
+				HasPlanError: true,
+				ExitCode:     1,
+			},
+		},
+		{
+			name: "invalid JSON",
+			body: "{",
+			result: ParseResult{
+				HasParseError: true,
+				ExitCode:      1,
+			},
+		},
+		{
+			name: "fallback to the text parser",
+			body: planFailureResult,
+			result: ParseResult{
+				Result: `Error: Error refreshing state: 4 error(s) occurred:
+
+* google_sql_database.main: 1 error(s) occurred:
+
+* google_sql_database.main: google_sql_database.main: Error reading SQL Database "main" in instance "main-master-instance": googleapi: Error 409: The instance or operation is not in an appropriate state to handle the request., invalidState
+* google_sql_user.proxyuser_main: 1 error(s) occurred:`,
+				HasPlanError: true,
+				ExitCode:     1,
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+			result := NewJSONPlanParser().Parse(testCase.body)
+			if diff := cmp.Diff(result, testCase.result, cmpopts.IgnoreUnexported(ParseResult{}), cmpopts.IgnoreFields(ParseResult{}, "Error")); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"regexp"
 	"strings"
+	"time"
 )
 
 // Parser is an interface for parsing terraform execution result
@@ -18,6 +19,7 @@ type ParseResult struct {
 	ChangedResult      string
 	Warning            string
 	HasAddOrUpdateOnly bool
+	HasChanges         bool
 	HasDestroy         bool
 	HasNoChanges       bool
 	HasPlanError       bool
@@ -28,6 +30,47 @@ type ParseResult struct {
 	UpdatedResources   []string
 	DeletedResources   []string
 	ReplacedResources  []string
+	MovedResources     []MovedResource
+	ImportedResources  []string
+	ForgottenResources []string
+	ChangedOutputs     []string
+	// Tool is the tool detected from the output such as ToolTerraform and ToolOpenTofu. It's empty if it's unknown
+	Tool string
+	// PolicyViolations are the violations of the policy by the plan. They're set by the notifier with EvaluatePolicy
+	PolicyViolations []PolicyViolation
+	// Modules are the results of the modules of terragrunt run-all. They're set by TerragruntPlanParser
+	Modules []ModuleResult
+	// AppliedResources are the operations on the resources performed by apply in the order they started.
+	// They're set by ApplyParser
+	AppliedResources []AppliedResource
+	// HasDrift means the real infrastructure doesn't match the state or the configuration. It's set by DriftParser
+	HasDrift bool
+	// DriftedResources are the resources which have changed outside of the tool. They're set by DriftParser
+	DriftedResources []string
+}
+
+// MovedResource represents a resource whose address is changed by a `moved` block
+type MovedResource struct {
+	From string
+	To   string
+}
+
+// ActionImport is the action of AppliedResource which imports the resource
+const ActionImport = "import"
+
+// AppliedResource represents the operation on a resource performed by apply
+type AppliedResource struct {
+	Address string
+	// Action is ActionCreate, ActionUpdate, ActionDelete, ActionReplace or ActionImport
+	Action string
+	// Duration is the time which the operation took. It's zero if the operation didn't complete
+	Duration time.Duration
+	// ID is the ID of the resource reported by the tool
+	ID string
+	// Failed means the operation didn't complete
+	Failed bool
+	// Error is the summary of the error of the resource if the operation failed
+	Error string
 }
 
 // DefaultParser is a parser for terraform commands
@@ -43,12 +86,32 @@ type PlanParser struct {
 	Update       *regexp.Regexp
 	Delete       *regexp.Regexp
 	Replace      *regexp.Regexp
+	Move         *regexp.Regexp
+	MovedFrom    *regexp.Regexp
+	Import       *regexp.Regexp
+	ImportedFrom *regexp.Regexp
+	Forget       *regexp.Regexp
+	Resource     *regexp.Regexp
+	// ChangeOutput is the line before the changes
+	ChangeOutput *regexp.Regexp
+	// OutsideChanges is the line before the changes outside of the tool
+	OutsideChanges *regexp.Regexp
 }
 
 // ApplyParser is a parser for terraform apply
 type ApplyParser struct {
 	Pass *regexp.Regexp
 	Fail *regexp.Regexp
+	// Start matches the line when the operation on a resource starts. The submatches are the address, the operation and the ID
+	Start *regexp.Regexp
+	// Complete matches the line when the operation on a resource completes. The submatches are the address, the operation, the duration and the ID
+	Complete *regexp.Regexp
+	// ImportComplete matches the line when the import of a resource completes. The submatches are the address and the ID
+	ImportComplete *regexp.Regexp
+	// ErrorSummary matches the summary of the error. The submatch is the summary
+	ErrorSummary *regexp.Regexp
+	// ErrorResource matches the resource which the error is about. The submatch is the address
+	ErrorResource *regexp.Regexp
 }
 
 // NewDefaultParser is DefaultParser initializer
@@ -68,6 +131,16 @@ func NewPlanParser() *PlanParser {
 		Update:       regexp.MustCompile(`^ *# (.*) will be updated in-place$`),
 		Delete:       regexp.MustCompile(`^ *# (.*) will be destroyed$`),
 		Replace:      regexp.MustCompile(`^ *# (.*) must be replaced$`),
+		Move:         regexp.MustCompile(`^ *# (.*) has moved to (.*)$`),
+		MovedFrom:    regexp.MustCompile(`^ *# \(moved from (.*)\)$`),
+		Import:       regexp.MustCompile(`^ *# (.*) will be imported$`),
+		ImportedFrom: regexp.MustCompile(`^ *# \(imported from (.*)\)$`),
+		Forget:       regexp.MustCompile(`^ *# (.*) will be removed from the \S+ state but will not be destroyed$`),
+		// the header line of each resource in the plan, e.g. "# aws_instance.foo will be updated in-place"
+		Resource: regexp.MustCompile(`^ *# ([^(].*?) (?:will|must|has) `),
+		// OpenTofu outputs its name instead of Terraform
+		ChangeOutput:   regexp.MustCompile(`^(?:Terraform|OpenTofu) will perform the following actions:$`),
+		OutsideChanges: regexp.MustCompile(`^Note: Objects have changed outside of (?:Terraform|OpenTofu)$`),
 	}
 }
 
@@ -76,6 +149,13 @@ func NewApplyParser() *ApplyParser {
 	return &ApplyParser{
 		Pass: regexp.MustCompile(`(?m)^(Apply complete!)`),
 		Fail: regexp.MustCompile(`(?m)^(Error: )`),
+		// e.g. "aws_instance.foo: Creating..." and "aws_instance.foo (deposed object 1a2b3c4d): Destroying... [id=i-1234]"
+		Start: regexp.MustCompile(`^(.+?)(?: \(deposed object \S+\))?: (Creating|Modifying|Destroying|Importing)\.\.\.(?: \[id=(.*)\])?$`),
+		// e.g. "aws_instance.foo: Creation complete after 3s [id=i-1234]"
+		Complete:       regexp.MustCompile(`^(.+?)(?: \(deposed object \S+\))?: (Creation|Modifications|Destruction) complete after (\S+)(?: \[id=(.*)\])?$`),
+		ImportComplete: regexp.MustCompile(`^(.+?): Import complete(?: \[id=(.*)\])?$`),
+		ErrorSummary:   regexp.MustCompile(`^[│╷ ]*Error: (.*)$`),
+		ErrorResource:  regexp.MustCompile(`^[│ ]*with (.+),$`),
 	}
 }
 
@@ -114,7 +194,10 @@ func (p *PlanParser) Parse(body string) ParseResult { //nolint:cyclop
 	lines := strings.Split(body, "\n")
 	firstMatchLineIndex := -1
 	var result, firstMatchLine string
-	var createdResources, updatedResources, deletedResources, replacedResources []string
+	var createdResources, updatedResources, deletedResources, replacedResources, importedResources, forgottenResources []string
+	var movedResources []MovedResource
+	// the address of the resource which is currently read. It's used to associate "(moved from ...)" and "(imported from ...)" with the resource
+	currentResource := ""
 	startOutsideTerraform := -1
 	endOutsideTerraform := -1
 	startChangeOutput := -1
@@ -122,13 +205,13 @@ func (p *PlanParser) Parse(body string) ParseResult { //nolint:cyclop
 	startWarning := -1
 	endWarning := -1
 	for i, line := range lines {
-		if line == "Note: Objects have changed outside of Terraform" { // https://github.com/hashicorp/terraform/blob/332045a4e4b1d256c45f98aac74e31102ace7af7/internal/command/views/plan.go#L403
+		if p.OutsideChanges.MatchString(line) { // https://github.com/hashicorp/terraform/blob/332045a4e4b1d256c45f98aac74e31102ace7af7/internal/command/views/plan.go#L403
 			startOutsideTerraform = i + 1
 		}
-		if startOutsideTerraform != -1 && endOutsideTerraform == -1 && strings.HasPrefix(line, "Unless you have made equivalent changes to your configuration") { // https://github.com/hashicorp/terraform/blob/332045a4e4b1d256c45f98aac74e31102ace7af7/internal/command/views/plan.go#L110
+		if startOutsideTerraform != -1 && endOutsideTerraform == -1 && isEndOfOutsideChanges(line) {
 			endOutsideTerraform = i + 1
 		}
-		if line == "Terraform will perform the following actions:" { // https://github.com/hashicorp/terraform/blob/332045a4e4b1d256c45f98aac74e31102ace7af7/internal/command/views/plan.go#L252
+		if p.ChangeOutput.MatchString(line) { // https://github.com/hashicorp/terraform/blob/332045a4e4b1d256c45f98aac74e31102ace7af7/internal/command/views/plan.go#L252
 			startChangeOutput = i + 1
 		}
 		if startChangeOutput != -1 && endChangeOutput == -1 && strings.HasPrefix(line, "Plan: ") { // https://github.com/hashicorp/terraform/blob/dfc12a6a9e1cff323829026d51873c1b80200757/internal/command/views/plan.go#L306
@@ -146,6 +229,21 @@ func (p *PlanParser) Parse(body string) ParseResult { //nolint:cyclop
 				firstMatchLine = line
 			}
 		}
+		if rsc := extractResource(p.Resource, line); rsc != "" {
+			currentResource = rsc
+		}
+		if arr := p.Move.FindStringSubmatch(line); len(arr) == 3 { //nolint:gomnd
+			movedResources = append(movedResources, MovedResource{From: arr[1], To: arr[2]})
+			continue
+		}
+		if rsc := extractResource(p.MovedFrom, line); rsc != "" && currentResource != "" {
+			movedResources = append(movedResources, MovedResource{From: rsc, To: currentResource})
+			continue
+		}
+		if rsc := extractResource(p.ImportedFrom, line); rsc != "" && currentResource != "" {
+			importedResources = append(importedResources, currentResource)
+			continue
+		}
 		if rsc := extractResource(p.Create, line); rsc != "" {
 			createdResources = append(createdResources, rsc)
 		} else if rsc := extractResource(p.Update, line); rsc != "" {
@@ -154,6 +252,10 @@ func (p *PlanParser) Parse(body string) ParseResult { //nolint:cyclop
 			deletedResources = append(deletedResources, rsc)
 		} else if rsc := extractResource(p.Replace, line); rsc != "" {
 			replacedResources = append(replacedResources, rsc)
+		} else if rsc := extractResource(p.Import, line); rsc != "" {
+			importedResources = append(importedResources, rsc)
+		} else if rsc := extractResource(p.Forget, line); rsc != "" {
+			forgottenResources = append(forgottenResources, rsc)
 		}
 	}
 	var hasPlanError bool
@@ -171,6 +273,9 @@ func (p *PlanParser) Parse(body string) ParseResult { //nolint:cyclop
 
 	outsideTerraform := ""
 	if startOutsideTerraform != -1 {
+		if endOutsideTerraform == -1 {
+			endOutsideTerraform = len(lines)
+		}
 		outsideTerraform = strings.Join(lines[startOutsideTerraform:endOutsideTerraform], "\n")
 	}
 
@@ -194,6 +299,7 @@ func (p *PlanParser) Parse(body string) ParseResult { //nolint:cyclop
 		OutsideTerraform:   outsideTerraform,
 		Warning:            warnings,
 		HasAddOrUpdateOnly: HasAddOrUpdateOnly,
+		HasChanges:         !hasNoChanges && !hasPlanError,
 		HasDestroy:         hasDestroy,
 		HasNoChanges:       hasNoChanges,
 		HasPlanError:       hasPlanError,
@@ -203,6 +309,10 @@ func (p *PlanParser) Parse(body string) ParseResult { //nolint:cyclop
 		UpdatedResources:   updatedResources,
 		DeletedResources:   deletedResources,
 		ReplacedResources:  replacedResources,
+		MovedResources:     movedResources,
+		ImportedResources:  importedResources,
+		ForgottenResources: forgottenResources,
+		Tool:               detectToolFromOutput(body),
 	}
 }
 
@@ -237,12 +347,94 @@ func (p *ApplyParser) Parse(body string) ParseResult {
 		result = strings.Join(trimLastNewline(lines[i:]), "\n")
 	}
 	return ParseResult{
-		Result:   result,
-		ExitCode: exitCode,
-		Error:    nil,
+		Result:           result,
+		ExitCode:         exitCode,
+		Error:            nil,
+		Tool:             detectToolFromOutput(body),
+		AppliedResources: p.appliedResources(lines),
 	}
 }
 
+// appliedResources returns the operations on the resources in the order they started.
+// A resource which is destroyed and created again is merged into ActionReplace.
+// The operations which didn't complete are failed, and the errors are associated by "with <address>," of the diagnostics.
+func (p *ApplyParser) appliedResources(lines []string) []AppliedResource { //nolint:cyclop
+	var resources []AppliedResource
+	index := map[string]int{}
+	completed := map[string]bool{}
+	errorSummary := ""
+	for _, line := range lines {
+		if arr := p.Start.FindStringSubmatch(line); arr != nil {
+			address, action := arr[1], startActions[arr[2]]
+			i, ok := index[address]
+			if !ok {
+				index[address] = len(resources)
+				resources = append(resources, AppliedResource{Address: address, Action: action, ID: arr[3]})
+				continue
+			}
+			r := &resources[i]
+			if (r.Action == ActionDelete && action == ActionCreate) || (r.Action == ActionCreate && action == ActionDelete) {
+				r.Action = ActionReplace
+			}
+			completed[address] = false
+			continue
+		}
+		if arr := p.Complete.FindStringSubmatch(line); arr != nil {
+			i, ok := index[arr[1]]
+			if !ok {
+				continue
+			}
+			r := &resources[i]
+			if d, err := time.ParseDuration(arr[3]); err == nil {
+				r.Duration += d
+			}
+			if arr[4] != "" {
+				r.ID = arr[4]
+			}
+			completed[arr[1]] = true
+			continue
+		}
+		if arr := p.ImportComplete.FindStringSubmatch(line); arr != nil {
+			if i, ok := index[arr[1]]; ok {
+				if arr[2] != "" {
+					resources[i].ID = arr[2]
+				}
+				completed[arr[1]] = true
+			}
+			continue
+		}
+		if arr := p.ErrorSummary.FindStringSubmatch(line); arr != nil {
+			errorSummary = arr[1]
+			continue
+		}
+		if arr := p.ErrorResource.FindStringSubmatch(line); arr != nil && errorSummary != "" {
+			if i, ok := index[arr[1]]; ok && !completed[arr[1]] {
+				resources[i].Error = errorSummary
+			}
+			errorSummary = ""
+		}
+	}
+	for i := range resources {
+		resources[i].Failed = !completed[resources[i].Address]
+	}
+	return resources
+}
+
+var startActions = map[string]string{ //nolint:gochecknoglobals
+	"Creating":   ActionCreate,
+	"Modifying":  ActionUpdate,
+	"Destroying": ActionDelete,
+	"Importing":  ActionImport,
+}
+
+// isEndOfOutsideChanges returns true if the line is the last line of the changes outside of the tool
+func isEndOfOutsideChanges(line string) bool {
+	// https://github.com/hashicorp/terraform/blob/332045a4e4b1d256c45f98aac74e31102ace7af7/internal/command/views/plan.go#L110
+	return strings.HasPrefix(line, "Unless you have made equivalent changes to your configuration") ||
+		// the plan with -refresh-only
+		strings.HasPrefix(line, "This is a refresh-only plan")
+}
+
 func trimLastNewline(s []string) []string {
 	if len(s) == 0 {
 		return s
@@ -3,6 +3,7 @@ package terraform
 import (
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
@@ -240,6 +241,73 @@ can't guarantee that exactly these actions will be performed if
 "terraform apply" is subsequently run.
 `
 
+const planHasMovedImportedAndForgotten = `
+Terraform used the selected providers to generate the following execution
+plan. Resource actions are indicated with the following symbols:
+  ~ update in-place
+
+Terraform will perform the following actions:
+
+  # aws_instance.old has moved to aws_instance.new
+    resource "aws_instance" "new" {
+        id = "i-0123456789"
+    }
+
+  # aws_instance.renamed will be updated in-place
+  # (moved from aws_instance.legacy)
+  ~ resource "aws_instance" "renamed" {
+      ~ instance_type = "t3.micro" -> "t3.small"
+    }
+
+  # aws_s3_bucket.imported will be imported
+    resource "aws_s3_bucket" "imported" {
+        bucket = "imported"
+    }
+
+  # aws_s3_bucket.adopted will be updated in-place
+  # (imported from "adopted")
+  ~ resource "aws_s3_bucket" "adopted" {
+      ~ tags = {}
+    }
+
+  # aws_iam_user.legacy will be removed from the Terraform state but will not be destroyed
+  . resource "aws_iam_user" "legacy" {
+        name = "legacy"
+    }
+
+Plan: 2 to import, 0 to add, 2 to change, 0 to destroy, 1 to forget.
+`
+
+const planOpenTofu = `
+Note: Objects have changed outside of OpenTofu
+
+OpenTofu detected the following changes made outside of OpenTofu since the
+last "tofu apply" which may have affected this plan:
+
+  # aws_s3_bucket.logs has changed
+  ~ resource "aws_s3_bucket" "logs" {
+      ~ tags = {}
+    }
+
+Unless you have made equivalent changes to your configuration, or ignored the
+relevant attributes using ignore_changes, the following plan may include
+actions to undo or respond to these changes.
+
+─────────────────────────────────────────────────────────────────────────────
+
+OpenTofu used the selected providers to generate the following execution
+plan. Resource actions are indicated with the following symbols:
+  - destroy
+
+OpenTofu will perform the following actions:
+
+  # aws_s3_bucket.logs will be destroyed
+  - resource "aws_s3_bucket" "logs" {
+    }
+
+Plan: 0 to add, 0 to change, 1 to destroy.
+`
+
 const applySuccessResult = `
 data.terraform_remote_state.teams_platform_development: Refreshing state...
 google_project.my_service: Refreshing state...
@@ -297,6 +365,23 @@ Error: Batch "project/tfcmt-jp-tfcmt-prod/services:batchEnable" for request "Ena
 
 `
 
+const applyResourcesResult = `
+aws_instance.foo: Creating...
+aws_instance.bar: Destroying... [id=i-0001]
+aws_s3_bucket.logs: Destroying... [id=logs]
+aws_instance.bar: Destruction complete after 1s
+aws_instance.bar: Creating...
+aws_instance.foo: Still creating... [10s elapsed]
+aws_instance.foo: Creation complete after 1m2s [id=i-1234]
+aws_instance.bar: Creation complete after 12s [id=i-0002]
+
+Error: deleting S3 Bucket (logs): BucketNotEmpty
+
+  with aws_s3_bucket.logs,
+  on main.tf line 1, in resource "aws_s3_bucket" "logs":
+   1: resource "aws_s3_bucket" "logs" {
+`
+
 func TestDefaultParserParse(t *testing.T) {
 	t.Parallel()
 	testCases := []struct {
@@ -333,6 +418,8 @@ func TestPlanParserParse(t *testing.T) {
 			result: ParseResult{
 				Result:             "Plan: 1 to add, 0 to change, 0 to destroy.",
 				HasAddOrUpdateOnly: true,
+				HasChanges:         true,
+				Tool:               ToolTerraform,
 				HasDestroy:         false,
 				HasNoChanges:       false,
 				HasPlanError:       false,
@@ -403,6 +490,8 @@ Plan: 1 to add, 0 to change, 0 to destroy.`,
 				Result:             "Plan: 0 to add, 0 to change, 1 to destroy.",
 				HasAddOrUpdateOnly: false,
 				HasDestroy:         true,
+				HasChanges:         true,
+				Tool:               ToolTerraform,
 				HasNoChanges:       false,
 				HasPlanError:       false,
 				ExitCode:           0,
@@ -421,6 +510,8 @@ Plan: 0 to add, 0 to change, 1 to destroy.`,
 				Result:             "Plan: 1 to add, 0 to change, 1 to destroy.",
 				HasAddOrUpdateOnly: false,
 				HasDestroy:         true,
+				HasChanges:         true,
+				Tool:               ToolTerraform,
 				HasNoChanges:       false,
 				HasPlanError:       false,
 				ExitCode:           0,
@@ -445,6 +536,8 @@ Plan: 1 to add, 0 to change, 1 to destroy.`,
 			result: ParseResult{
 				Result:             "Plan: 1 to add, 1 to change, 0 to destroy.",
 				HasAddOrUpdateOnly: true,
+				HasChanges:         true,
+				Tool:               ToolTerraform,
 				HasDestroy:         false,
 				HasNoChanges:       false,
 				HasPlanError:       false,
@@ -464,6 +557,80 @@ Plan: 1 to add, 0 to change, 1 to destroy.`,
 Plan: 1 to add, 1 to change, 0 to destroy.`,
 			},
 		},
+		{
+			name: "plan has moved, imported and forgotten resources",
+			body: planHasMovedImportedAndForgotten,
+			result: ParseResult{
+				Result:             "Plan: 2 to import, 0 to add, 2 to change, 0 to destroy, 1 to forget.",
+				HasAddOrUpdateOnly: true,
+				HasChanges:         true,
+				Tool:               ToolTerraform,
+				ExitCode:           0,
+				ChangedResult: `
+  # aws_instance.old has moved to aws_instance.new
+    resource "aws_instance" "new" {
+        id = "i-0123456789"
+    }
+
+  # aws_instance.renamed will be updated in-place
+  # (moved from aws_instance.legacy)
+  ~ resource "aws_instance" "renamed" {
+      ~ instance_type = "t3.micro" -> "t3.small"
+    }
+
+  # aws_s3_bucket.imported will be imported
+    resource "aws_s3_bucket" "imported" {
+        bucket = "imported"
+    }
+
+  # aws_s3_bucket.adopted will be updated in-place
+  # (imported from "adopted")
+  ~ resource "aws_s3_bucket" "adopted" {
+      ~ tags = {}
+    }
+
+  # aws_iam_user.legacy will be removed from the Terraform state but will not be destroyed
+  . resource "aws_iam_user" "legacy" {
+        name = "legacy"
+    }
+
+Plan: 2 to import, 0 to add, 2 to change, 0 to destroy, 1 to forget.`,
+				UpdatedResources: []string{"aws_instance.renamed", "aws_s3_bucket.adopted"},
+				MovedResources: []MovedResource{
+					{From: "aws_instance.old", To: "aws_instance.new"},
+					{From: "aws_instance.legacy", To: "aws_instance.renamed"},
+				},
+				ImportedResources:  []string{"aws_s3_bucket.imported", "aws_s3_bucket.adopted"},
+				ForgottenResources: []string{"aws_iam_user.legacy"},
+			},
+		},
+		{
+			name: "plan of opentofu",
+			body: planOpenTofu,
+			result: ParseResult{
+				Result:     "Plan: 0 to add, 0 to change, 1 to destroy.",
+				HasDestroy: true,
+				HasChanges: true,
+				OutsideTerraform: `
+OpenTofu detected the following changes made outside of OpenTofu since the
+last "tofu apply" which may have affected this plan:
+
+  # aws_s3_bucket.logs has changed
+  ~ resource "aws_s3_bucket" "logs" {
+      ~ tags = {}
+    }
+
+Unless you have made equivalent changes to your configuration, or ignored the`,
+				ChangedResult: `
+  # aws_s3_bucket.logs will be destroyed
+  - resource "aws_s3_bucket" "logs" {
+    }
+
+Plan: 0 to add, 0 to change, 1 to destroy.`,
+				DeletedResources: []string{"aws_s3_bucket.logs"},
+				Tool:             ToolOpenTofu,
+			},
+		},
 	}
 	for _, testCase := range testCases {
 		testCase := testCase
@@ -517,6 +684,23 @@ func TestApplyParserParse(t *testing.T) {
 				Error:    nil,
 			},
 		},
+		{
+			name: "applied resources",
+			body: applyResourcesResult,
+			result: ParseResult{
+				Result: `Error: deleting S3 Bucket (logs): BucketNotEmpty
+
+  with aws_s3_bucket.logs,
+  on main.tf line 1, in resource "aws_s3_bucket" "logs":
+   1: resource "aws_s3_bucket" "logs" {`,
+				ExitCode: 1,
+				AppliedResources: []AppliedResource{
+					{Address: "aws_instance.foo", Action: ActionCreate, Duration: 62 * time.Second, ID: "i-1234"},
+					{Address: "aws_instance.bar", Action: ActionReplace, Duration: 13 * time.Second, ID: "i-0002"},
+					{Address: "aws_s3_bucket.logs", Action: ActionDelete, ID: "logs", Failed: true, Error: "deleting S3 Bucket (logs): BucketNotEmpty"},
+				},
+			},
+		},
 	}
 	for _, testCase := range testCases {
 		testCase := testCase
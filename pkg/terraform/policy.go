@@ -0,0 +1,118 @@
+package terraform
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// The actions of the resource changes which policy rules match
+const (
+	ActionCreate  = "create"
+	ActionUpdate  = "update"
+	ActionDelete  = "delete"
+	ActionReplace = "replace"
+)
+
+// PolicyRule is a rule of the changes which aren't allowed by the policy
+type PolicyRule struct {
+	Name string
+	// Actions are the actions which the rule matches. If it's empty, the rule matches all actions
+	Actions []string
+	// Resources are the globs of the resource addresses which the rule matches such as aws_db_instance.* .
+	// "*" matches any string including ".". If it's empty, the rule matches all resources
+	Resources []string
+	// MaxChanges is the maximum number of the matched changes.
+	// If it's zero, any matched change violates the rule
+	MaxChanges int
+}
+
+// PolicyViolation is a violation of a policy rule
+type PolicyViolation struct {
+	Rule string
+	// Address is the address of the resource. It's empty if the number of the changes exceeds MaxChanges
+	Address string
+	Action  string
+	Message string
+}
+
+type resourceChange struct {
+	action  string
+	address string
+}
+
+// EvaluatePolicy returns the violations of the rules by the resource changes of the plan result
+func EvaluatePolicy(rules []PolicyRule, result ParseResult) []PolicyViolation {
+	changes := make([]resourceChange, 0, len(result.CreatedResources)+len(result.UpdatedResources)+len(result.DeletedResources)+len(result.ReplacedResources))
+	for action, addresses := range map[string][]string{
+		ActionCreate:  result.CreatedResources,
+		ActionUpdate:  result.UpdatedResources,
+		ActionDelete:  result.DeletedResources,
+		ActionReplace: result.ReplacedResources,
+	} {
+		for _, address := range addresses {
+			changes = append(changes, resourceChange{action: action, address: address})
+		}
+	}
+	slices.SortFunc(changes, func(a, b resourceChange) int {
+		return strings.Compare(a.address+" "+a.action, b.address+" "+b.action)
+	})
+
+	var violations []PolicyViolation
+	for _, rule := range rules {
+		patterns := make([]*regexp.Regexp, len(rule.Resources))
+		for i, glob := range rule.Resources {
+			patterns[i] = globToRegexp(glob)
+		}
+		var matched []resourceChange
+		for _, change := range changes {
+			if len(rule.Actions) != 0 && !slices.Contains(rule.Actions, change.action) {
+				continue
+			}
+			if len(patterns) != 0 && !slices.ContainsFunc(patterns, func(p *regexp.Regexp) bool {
+				return p.MatchString(change.address)
+			}) {
+				continue
+			}
+			matched = append(matched, change)
+		}
+
+		if rule.MaxChanges > 0 {
+			if len(matched) > rule.MaxChanges {
+				violations = append(violations, PolicyViolation{
+					Rule:    rule.Name,
+					Message: fmt.Sprintf("%d changes exceed the limit of %d", len(matched), rule.MaxChanges),
+				})
+			}
+			continue
+		}
+		for _, change := range matched {
+			violations = append(violations, PolicyViolation{
+				Rule:    rule.Name,
+				Address: change.address,
+				Action:  change.action,
+				Message: fmt.Sprintf("%s %s is denied", change.action, change.address),
+			})
+		}
+	}
+	return violations
+}
+
+// globToRegexp converts a glob to a regular expression. "*" matches any string and "?" matches any character
+func globToRegexp(glob string) *regexp.Regexp {
+	var b strings.Builder
+	b.WriteString("^")
+	for _, r := range glob {
+		switch r {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.MustCompile(b.String())
+}
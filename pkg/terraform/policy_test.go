@@ -0,0 +1,68 @@
+package terraform
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestEvaluatePolicy(t *testing.T) {
+	t.Parallel()
+	result := ParseResult{
+		CreatedResources:  []string{"aws_instance.web", "module.prod.aws_s3_bucket.logs"},
+		UpdatedResources:  []string{"aws_db_instance.main"},
+		DeletedResources:  []string{`aws_db_instance.replica["a"]`},
+		ReplacedResources: []string{"module.prod.aws_instance.app"},
+	}
+	testCases := []struct {
+		name  string
+		rules []PolicyRule
+		exp   []PolicyViolation
+	}{
+		{
+			name: "no rule",
+		},
+		{
+			name: "deny deletion and replacement of the matched resources",
+			rules: []PolicyRule{
+				{
+					Name:      "protect-databases",
+					Actions:   []string{ActionDelete, ActionReplace},
+					Resources: []string{"aws_db_instance.*", "module.prod.*"},
+				},
+			},
+			exp: []PolicyViolation{
+				{Rule: "protect-databases", Address: `aws_db_instance.replica["a"]`, Action: ActionDelete, Message: `delete aws_db_instance.replica["a"] is denied`},
+				{Rule: "protect-databases", Address: "module.prod.aws_instance.app", Action: ActionReplace, Message: "replace module.prod.aws_instance.app is denied"},
+			},
+		},
+		{
+			name: "cap the number of changes",
+			rules: []PolicyRule{
+				{Name: "small-changes", MaxChanges: 3},
+				{Name: "few-creations", Actions: []string{ActionCreate}, MaxChanges: 2},
+			},
+			exp: []PolicyViolation{
+				{Rule: "small-changes", Message: "5 changes exceed the limit of 3"},
+			},
+		},
+		{
+			name: "? matches a character",
+			rules: []PolicyRule{
+				{Resources: []string{"aws_instance.we?"}},
+			},
+			exp: []PolicyViolation{
+				{Address: "aws_instance.web", Action: ActionCreate, Message: "create aws_instance.web is denied"},
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+			if diff := cmp.Diff(testCase.exp, EvaluatePolicy(testCase.rules, result)); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
@@ -2,7 +2,9 @@ package terraform
 
 import (
 	"bytes"
+	"fmt"
 	htmltemplate "html/template"
+	"io"
 	"strings"
 	texttemplate "text/template"
 
@@ -16,12 +18,13 @@ const (
 
 {{if .Link}}[CI link]({{.Link}}){{end}}
 
-{{template "deletion_warning" .}}
+{{template "deletion_warning" .}}{{template "policy_violations" .}}
 {{template "result" .}}
-{{template "updated_resources" .}}
-
+{{if .Modules}}{{template "modules" .}}{{else}}{{template "updated_resources" .}}{{end}}
+{{if .FullOutputURL}}
+{{template "full_output_link" .}}{{else}}
 {{template "changed_result" .}}
-{{template "change_outside_terraform" .}}
+{{template "change_outside_terraform" .}}{{end}}
 {{template "warning" .}}
 {{template "error_messages" .}}`
 
@@ -29,15 +32,45 @@ const (
 	DefaultApplyTemplate = `
 {{template "apply_title" .}}
 
-{{if .Link}}[CI link]({{.Link}}){{end}}
+{{if .Link}}[CI link]({{.Link}}){{end}}{{template "plan_link" .}}
 
 {{if ne .ExitCode 0}}{{template "guide_apply_failure" .}}{{end}}
 
-{{template "result" .}}
-
+{{template "result" .}}{{template "applied_resources" .}}
+{{if .FullOutputURL}}
+{{template "full_output_link" .}}
+{{else}}
 <details><summary>Details (Click me)</summary>
 {{wrapCode .CombinedOutput}}
 </details>
+{{end}}{{template "error_messages" .}}`
+
+	// DefaultPlanAllTemplate is a default template for terraform plan of multiple targets
+	DefaultPlanAllTemplate = `
+{{template "plan_all_title" .}}
+
+{{if .Link}}[CI link]({{.Link}}){{end}}
+
+{{template "deletion_warning" .}}
+{{template "targets_summary" .}}
+{{template "targets_details" .}}{{if .FullOutputURL}}
+{{template "full_output_link" .}}
+{{end}}
+{{template "error_messages" .}}`
+
+	// DefaultDriftTemplate is a default template for the drift detection.
+	// It's used as the description of the issue
+	DefaultDriftTemplate = `
+{{template "drift_title" .}}
+
+{{if .Link}}[CI link]({{.Link}}){{end}}
+
+{{template "result" .}}
+{{template "drifted_resources" .}}
+{{template "updated_resources" .}}
+{{template "changed_result" .}}
+{{template "change_outside_terraform" .}}
+{{template "warning" .}}
 {{template "error_messages" .}}`
 
 	// DefaultPlanParseErrorTemplate is a default template for terraform plan parse error
@@ -47,11 +80,13 @@ const (
 {{if .Link}}[CI link]({{.Link}}){{end}}
 
 It failed to parse the result.
-
+{{if .FullOutputURL}}
+{{template "full_output_link" .}}
+{{else}}
 <details><summary>Details (Click me)</summary>
 {{wrapCode .CombinedOutput}}
 </details>
-`
+{{end}}`
 
 	// DefaultApplyParseErrorTemplate  is a default template for terraform apply parse error
 	DefaultApplyParseErrorTemplate = `
@@ -62,15 +97,70 @@ It failed to parse the result.
 {{template "guide_apply_parse_error" .}}
 
 It failed to parse the result.
-
+{{if .FullOutputURL}}
+{{template "full_output_link" .}}
+{{else}}
 <details><summary>Details (Click me)</summary>
 {{wrapCode .CombinedOutput}}
 </details>
-`
+{{end}}`
+
+	// toolPrefix prefixes the titles with the tool other than Terraform not to change the titles of the existing comments
+	toolPrefix = `{{if and .Tool (ne .Tool "Terraform")}}{{.Tool}} {{end}}`
+
+	planTitleTemplate = "## {{if eq .ExitCode 1}}:x: " + toolPrefix + "Plan Failed{{else}}" + toolPrefix + "Plan Result{{end}}{{if .Vars.target}} ({{.Vars.target}}){{end}}"
+
+	applyTitleTemplate = "## {{if eq .ExitCode 0}}:white_check_mark: " + toolPrefix + "Apply Succeeded{{else}}:x: " + toolPrefix + "Apply Failed{{end}}{{if .Vars.target}} ({{.Vars.target}}){{end}}"
 
-	planTitleTemplate = "## {{if eq .ExitCode 1}}:x: Plan Failed{{else}}Plan Result{{end}}{{if .Vars.target}} ({{.Vars.target}}){{end}}"
+	planAllTitleTemplate = "## {{if eq .ExitCode 1}}:x: " + toolPrefix + "Plan Failed{{else}}" + toolPrefix + "Plan Result{{end}} ({{len .Targets}} targets){{if .Vars.target}} ({{.Vars.target}}){{end}}"
 
-	applyTitleTemplate = "## {{if eq .ExitCode 0}}:white_check_mark: Apply Succeeded{{else}}:x: Apply Failed{{end}}{{if .Vars.target}} ({{.Vars.target}}){{end}}"
+	driftTitleTemplate = "## :warning: " + toolPrefix + "Drift Detected{{if .Vars.target}} ({{.Vars.target}}){{end}}"
+
+	driftedResourcesTemplate = `{{if .DriftedResources}}
+* Changed outside of {{if .Tool}}{{.Tool}}{{else}}Terraform{{end}}
+{{- range .DriftedResources}}
+  * {{.}}
+{{- end}}{{end}}`
+
+	targetStatusTemplate = "{{if .HasParseError}}:warning: Parse Error{{else if .HasPlanError}}:x: Error{{else if .PolicyViolations}}:no_entry: Policy Violation{{else if .HasDestroy}}:warning: Destroy{{else if .HasNoChanges}}No Changes{{else}}Changes{{end}}"
+
+	targetsSummaryTemplate = `{{if .Targets}}
+| Target | Result | Create | Update | Delete | Replace |
+|--------|--------|-------:|-------:|-------:|--------:|
+{{- range .Targets}}
+| {{.Target}} | {{template "target_status" .}} | {{len .CreatedResources}} | {{len .UpdatedResources}} | {{len .DeletedResources}} | {{len .ReplacedResources}} |
+{{- end}}
+{{end}}`
+
+	targetsDetailsTemplate = `{{range .Targets}}
+<details><summary>{{.Target}}: {{template "target_status" .}}</summary>
+
+{{if .HasParseError}}It failed to parse the result.
+{{wrapCode .CombinedOutput}}{{else}}{{template "policy_violations" .}}{{template "result" .}}
+{{template "updated_resources" .}}
+{{template "changed_result" .}}{{if .Warning}}
+{{wrapCode .Warning}}{{end}}{{end}}
+</details>
+{{end}}`
+
+	modulesTemplate = `{{if .Modules}}
+| Module | Result | Create | Update | Delete | Replace |
+|--------|--------|-------:|-------:|-------:|--------:|
+{{- range .Modules}}
+| {{.Path}} | {{template "target_status" .}} | {{len .CreatedResources}} | {{len .UpdatedResources}} | {{len .DeletedResources}} | {{len .ReplacedResources}} |
+{{- end}}
+{{range .Modules}}
+<details><summary>{{.Path}}: {{template "target_status" .}}</summary>
+
+{{if .HasParseError}}It failed to parse the result.
+{{wrapCode .Output}}{{else}}{{template "result" .}}
+{{template "updated_resources" .}}
+{{template "changed_result" .}}{{if .Warning}}
+{{wrapCode .Warning}}{{end}}{{end}}
+</details>
+{{end}}{{end}}`
+
+	fullOutputLinkTemplate = ":page_facing_up: [Full output]({{.FullOutputURL}})"
 
 	resultTemplate = "{{if .Result}}<pre><code>{{ .Result }}</code></pre>{{end}}"
 
@@ -90,11 +180,67 @@ It failed to parse the result.
 * Replace
 {{- range .ReplacedResources}}
   * {{.}}
+{{- end}}{{end}}{{if .MovedResources}}
+* Move
+{{- range .MovedResources}}
+  * {{.From}} => {{.To}}
+{{- end}}{{end}}{{if .ImportedResources}}
+* Import
+{{- range .ImportedResources}}
+  * {{.}}
+{{- end}}{{end}}{{if .ForgottenResources}}
+* Forget
+{{- range .ForgottenResources}}
+  * {{.}}
+{{- end}}{{end}}{{if .ChangedOutputs}}
+* Change Outputs
+{{- range .ChangedOutputs}}
+  * {{.}}
+{{- end}}{{end}}`
+
+	appliedResourcesTemplate = `{{if .AppliedResources}}
+
+| Resource | Action | Duration | ID |
+|----------|--------|----------|----|
+{{- range .AppliedResources}}
+| ` + "`{{.Address}}`" + ` | {{if .Failed}}:x: {{.Action}}{{if .Error}}: {{replace "|" "\\|" .Error}}{{end}}{{else}}{{.Action}}{{end}} | {{if not .Failed}}{{.Duration}}{{end}} | {{.ID}} |
 {{- end}}{{end}}`
 
+	planLinkTemplate = `{{if .PlanLink}}
+
+:link: [The plan]({{.PlanLink}}) was applied.{{if .PlanCompared}}{{with .PlanDiffs}}
+
+:warning: The applied changes differ from the plan.
+{{if .Added}}
+* Applied but not planned
+{{- range .Added}}
+  * {{.Action}} ` + "`{{.Address}}`" + `
+{{- end}}{{end}}{{if .Missing}}
+* Planned but not applied
+{{- range .Missing}}
+  * {{.Action}} ` + "`{{.Address}}`" + `
+{{- end}}{{end}}{{if .Changed}}
+* Applied with a different action
+{{- range .Changed}}
+  * ` + "`{{.Address}}`" + `: {{.Planned}} -> {{.Applied}}
+{{- end}}{{end}}{{if .Counts}}
+| Action | Planned | Applied |
+|--------|---------|---------|
+{{- range .Counts}}
+| {{.Action}} | {{.Planned}} | {{.Applied}} |
+{{- end}}{{end}}{{else}} The applied changes match the plan.{{end}}{{end}}{{end}}`
+
 	deletionWarningTemplate = `{{if .HasDestroy}}
 ### :warning: Resource Deletion will happen :warning:
 This plan contains resource delete operation. Please check the plan result very carefully!
+{{end}}`
+
+	policyViolationsTemplate = `{{if .PolicyViolations}}
+### :no_entry: Policy Violations
+This plan violates the policy. The pipeline fails until the violations are resolved.
+{{range .PolicyViolations}}
+* {{if .Rule}}{{.Rule}}: {{end}}{{.Message}}
+{{- end}}
 {{end}}`
 
 	changedResultTemplate = `{{if .ChangedResult}}
@@ -104,9 +250,9 @@ This plan contains resource delete operation. Please check the plan result very
 {{end}}`
 
 	changeOutsideTerraformTemplate = `{{if .ChangeOutsideTerraform}}
-<details><summary>:information_source: Objects have changed outside of Terraform</summary>
-
-_This feature was introduced from [Terraform v0.15.4](https://github.com/hashicorp/terraform/releases/tag/v0.15.4)._
+<details><summary>:information_source: Objects have changed outside of {{if .Tool}}{{.Tool}}{{else}}Terraform{{end}}</summary>
+{{if or (not .Tool) (eq .Tool "Terraform")}}
+_This feature was introduced from [Terraform v0.15.4](https://github.com/hashicorp/terraform/releases/tag/v0.15.4)._{{end}}
 {{wrapCode .ChangeOutsideTerraform}}
 </details>
 {{end}}`
@@ -123,6 +269,14 @@ _This feature was introduced from [Terraform v0.15.4](https://github.com/hashico
 {{- end}}{{end}}`
 )
 
+const (
+	// DefaultMaxCodeLength is the default maximum length of the text wrapped by wrapCode.
+	// The maximum length of GitLab note is 1,000,000 characters and a comment can have multiple code blocks.
+	DefaultMaxCodeLength = 300000
+	// NoCodeLengthLimit disables the truncation of wrapCode
+	NoCodeLengthLimit = -1
+)
+
 // CommonTemplate represents template entities
 type CommonTemplate struct {
 	Result                 string
@@ -131,6 +285,7 @@ type CommonTemplate struct {
 	Warning                string
 	Link                   string
 	UseRawOutput           bool
+	HasChanges             bool
 	HasDestroy             bool
 	Vars                   map[string]string
 	Templates              map[string]string
@@ -143,11 +298,44 @@ type CommonTemplate struct {
 	UpdatedResources       []string
 	DeletedResources       []string
 	ReplacedResources      []string
+	MovedResources         []MovedResource
+	ImportedResources      []string
+	ForgottenResources     []string
+	ChangedOutputs         []string
+	Targets                []TargetResult
+	// FullOutputURL is the URL to the full output of the command which is uploaded outside of the comment
+	FullOutputURL string
+	// PolicyViolations are the violations of the policy by the plan
+	PolicyViolations []PolicyViolation
+	// Tool is the name of the tool which runs the command such as Terraform and OpenTofu
+	Tool string
+	// Modules are the results of the modules of terragrunt run-all
+	Modules []ModuleResult
+	// DriftedResources are the resources which have changed outside of the tool
+	DriftedResources []string
+	// AppliedResources are the operations on the resources performed by apply
+	AppliedResources []AppliedResource
+	// PlanLink is the link to the plan comment which the apply is based on
+	PlanLink string
+	// PlanCompared means the applied changes are compared with the planned changes
+	PlanCompared bool
+	// PlanDiffs are the differences between the planned and the applied changes. It's nil if the applied changes match the plan
+	PlanDiffs *ChangeDiffs
+}
+
+// TargetResult represents the result of each target when the command is run for multiple targets
+type TargetResult struct {
+	ParseResult
+	Target         string
+	CombinedOutput string
 }
 
 // Template is a default template for terraform commands
 type Template struct {
 	Template string
+	// MaxCodeLength is the maximum length of the text wrapped by wrapCode.
+	// If it's zero, DefaultMaxCodeLength is used. If it's NoCodeLengthLimit, the text isn't truncated.
+	MaxCodeLength int
 	CommonTemplate
 }
 
@@ -171,6 +359,26 @@ func NewApplyTemplate(template string) *Template {
 	}
 }
 
+// NewPlanAllTemplate is PlanAllTemplate initializer
+func NewPlanAllTemplate(template string) *Template {
+	if template == "" {
+		template = DefaultPlanAllTemplate
+	}
+	return &Template{
+		Template: template,
+	}
+}
+
+// NewDriftTemplate is DriftTemplate initializer
+func NewDriftTemplate(template string) *Template {
+	if template == "" {
+		template = DefaultDriftTemplate
+	}
+	return &Template{
+		Template: template,
+	}
+}
+
 func NewPlanParseErrorTemplate(template string) *Template {
 	if template == "" {
 		template = DefaultPlanParseErrorTemplate
@@ -193,15 +401,24 @@ func avoidHTMLEscape(text string) htmltemplate.HTML {
 	return htmltemplate.HTML(text) //nolint:gosec
 }
 
-func wrapCode(text string) interface{} {
-	if len(text) > 60000 { //nolint:gomnd
-		text = text[:20000] + `
+func newWrapCode(maxLength int) func(text string) interface{} {
+	return func(text string) interface{} {
+		return wrapCode(text, maxLength)
+	}
+}
+
+func wrapCode(text string, maxLength int) interface{} {
+	if maxLength > 0 && len(text) > maxLength {
+		// keep the first and last third of the text
+		n := maxLength / 3 //nolint:gomnd
+		text = text[:n] + fmt.Sprintf(`
 
 # ...
-# ... The maximum length of GitHub Comment is 65536, so the content is omitted by tfcmt.
+# ... The content is omitted by tfcmt because it exceeds %d characters.
+# ... To show the whole content, please change max_code_length or enable split_comment.
 # ...
 
-` + text[len(text)-20000:]
+`, maxLength) + text[len(text)-n:]
 	}
 	if strings.Contains(text, "```") {
 		return `<pre><code>` + text + `</code></pre>`
@@ -209,48 +426,67 @@ func wrapCode(text string) interface{} {
 	return htmltemplate.HTML("\n```hcl\n" + text + "\n```\n") //nolint:gosec
 }
 
-func generateOutput(kind, template string, data any, useRawOutput bool) (string, error) {
-	var b bytes.Buffer
+// executor is either text/template or html/template
+type executor interface {
+	Execute(wr io.Writer, data any) error
+}
 
+func parseTemplate(kind, template string, useRawOutput bool, maxCodeLength int) (executor, error) {
 	if useRawOutput {
-		tpl, err := texttemplate.New(kind).Funcs(texttemplate.FuncMap{
+		return texttemplate.New(kind).Funcs(texttemplate.FuncMap{
 			"avoidHTMLEscape": avoidHTMLEscape,
-			"wrapCode":        wrapCode,
+			"wrapCode":        newWrapCode(maxCodeLength),
 		}).Funcs(sprig.TxtFuncMap()).Parse(template)
-		if err != nil {
-			return "", err
-		}
-		if err := tpl.Execute(&b, data); err != nil {
-			return "", err
-		}
-	} else {
-		tpl, err := htmltemplate.New(kind).Funcs(htmltemplate.FuncMap{
-			"avoidHTMLEscape": avoidHTMLEscape,
-			"wrapCode":        wrapCode,
-		}).Funcs(sprig.FuncMap()).Parse(template)
-		if err != nil {
-			return "", err
-		}
-		if err := tpl.Execute(&b, data); err != nil {
-			return "", err
-		}
+	}
+	return htmltemplate.New(kind).Funcs(htmltemplate.FuncMap{
+		"avoidHTMLEscape": avoidHTMLEscape,
+		"wrapCode":        newWrapCode(maxCodeLength),
+	}).Funcs(sprig.FuncMap()).Parse(template)
+}
+
+func generateOutput(kind, template string, data any, useRawOutput bool, maxCodeLength int) (string, error) {
+	var b bytes.Buffer
+
+	tpl, err := parseTemplate(kind, template, useRawOutput, maxCodeLength)
+	if err != nil {
+		return "", err
+	}
+	if err := tpl.Execute(&b, data); err != nil {
+		return "", err
 	}
 
 	return b.String(), nil
 }
 
+// Validate parses the template without executing it
+func (t *Template) Validate() error {
+	_, err := parseTemplate("default", addTemplates(t.Template, t.Templates), t.UseRawOutput, t.maxCodeLength())
+	return err
+}
+
 // Execute binds the execution result of terraform command into template
 func (t *Template) Execute() (string, error) {
 	templates := map[string]string{
 		"plan_title":               planTitleTemplate,
+		"plan_all_title":           planAllTitleTemplate,
 		"apply_title":              applyTitleTemplate,
+		"drift_title":              driftTitleTemplate,
+		"drifted_resources":        driftedResourcesTemplate,
 		"result":                   resultTemplate,
 		"updated_resources":        updatedResourcesTemplate,
+		"applied_resources":        appliedResourcesTemplate,
+		"plan_link":                planLinkTemplate,
 		"deletion_warning":         deletionWarningTemplate,
+		"policy_violations":        policyViolationsTemplate,
 		"changed_result":           changedResultTemplate,
 		"change_outside_terraform": changeOutsideTerraformTemplate,
 		"warning":                  warningTemplate,
 		"error_messages":           errorMessagesTemplate,
+		"full_output_link":         fullOutputLinkTemplate,
+		"target_status":            targetStatusTemplate,
+		"targets_summary":          targetsSummaryTemplate,
+		"targets_details":          targetsDetailsTemplate,
+		"modules":                  modulesTemplate,
 		"guide_apply_failure":      "",
 		"guide_apply_parse_error":  "",
 	}
@@ -259,7 +495,7 @@ func (t *Template) Execute() (string, error) {
 		templates[k] = v
 	}
 
-	resp, err := generateOutput("default", addTemplates(t.Template, templates), t.CommonTemplate, t.UseRawOutput)
+	resp, err := generateOutput("default", addTemplates(t.Template, templates), t.CommonTemplate, t.UseRawOutput, t.maxCodeLength())
 	if err != nil {
 		return "", err
 	}
@@ -267,6 +503,13 @@ func (t *Template) Execute() (string, error) {
 	return resp, nil
 }
 
+func (t *Template) maxCodeLength() int {
+	if t.MaxCodeLength == 0 {
+		return DefaultMaxCodeLength
+	}
+	return t.MaxCodeLength
+}
+
 // SetValue sets template entities to CommonTemplate
 func (t *Template) SetValue(ct CommonTemplate) {
 	t.CommonTemplate = ct
@@ -307,8 +550,9 @@ func (t *Template) IsSamePlan(executedStr string) bool {
 		commonTemplate := CommonTemplate{
 			ExitCode: exitCode,
 			Vars:     t.Vars,
+			Tool:     t.Tool,
 		}
-		newTitle, err := generateOutput("default", planTitleTemplate, commonTemplate, t.UseRawOutput)
+		newTitle, err := generateOutput("default", planTitleTemplate, commonTemplate, t.UseRawOutput, t.maxCodeLength())
 		if err != nil {
 			return false
 		}
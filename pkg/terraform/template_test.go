@@ -1,7 +1,9 @@
 package terraform_test
 
 import (
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/hirosassa/tfcmt-gitlab/pkg/terraform"
@@ -124,3 +126,173 @@ func TestTemplate_IsSamePlan(t *testing.T) {
 		})
 	}
 }
+
+func TestTemplate_ExecutePlanAll(t *testing.T) {
+	t.Parallel()
+	templ := terraform.NewPlanAllTemplate("")
+
+	templ.SetValue(terraform.CommonTemplate{
+		ExitCode: 1,
+		Targets: []terraform.TargetResult{
+			{
+				Target: "envs/dev",
+				ParseResult: terraform.ParseResult{
+					Result:           "Plan: 1 to add, 0 to change, 0 to destroy.",
+					HasChanges:       true,
+					CreatedResources: []string{"null_resource.foo"},
+				},
+			},
+			{
+				Target: "envs/prod",
+				ParseResult: terraform.ParseResult{
+					Result:       "Error: Invalid reference",
+					HasPlanError: true,
+					ExitCode:     1,
+				},
+			},
+		},
+	})
+
+	got, err := templ.Execute()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expect := `
+## :x: Plan Failed (2 targets)
+
+
+
+
+
+| Target | Result | Create | Update | Delete | Replace |
+|--------|--------|-------:|-------:|-------:|--------:|
+| envs/dev | Changes | 1 | 0 | 0 | 0 |
+| envs/prod | :x: Error | 0 | 0 | 0 | 0 |
+
+
+<details><summary>envs/dev: Changes</summary>
+
+<pre><code>Plan: 1 to add, 0 to change, 0 to destroy.</code></pre>
+
+* Create
+  * null_resource.foo
+
+</details>
+
+<details><summary>envs/prod: :x: Error</summary>
+
+<pre><code>Error: Invalid reference</code></pre>
+
+
+</details>
+
+`
+	if diff := cmp.Diff(expect, got); diff != "" {
+		t.Errorf("Template.Execute result diff (-expect, +got)\n%s", diff)
+	}
+}
+
+func TestTemplate_ExecuteMaxCodeLength(t *testing.T) {
+	t.Parallel()
+	output := strings.Repeat("a", 30) + strings.Repeat("b", 30) + strings.Repeat("c", 30)
+	testCases := []struct {
+		name          string
+		maxCodeLength int
+		expect        string
+	}{
+		{
+			name:          "truncated",
+			maxCodeLength: 60,
+			expect: "\n```hcl\n" + strings.Repeat("a", 20) + `
+
+# ...
+# ... The content is omitted by tfcmt because it exceeds 60 characters.
+# ... To show the whole content, please change max_code_length or enable split_comment.
+# ...
+
+` + strings.Repeat("c", 20) + "\n```\n",
+		},
+		{
+			name:          "not truncated",
+			maxCodeLength: terraform.NoCodeLengthLimit,
+			expect:        "\n```hcl\n" + output + "\n```\n",
+		},
+		{
+			name:   "default",
+			expect: "\n```hcl\n" + output + "\n```\n",
+		},
+	}
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+			tpl := terraform.NewPlanTemplate("{{wrapCode .CombinedOutput}}")
+			tpl.MaxCodeLength = testCase.maxCodeLength
+			tpl.SetValue(terraform.CommonTemplate{CombinedOutput: output})
+			got, err := tpl.Execute()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(testCase.expect, got); diff != "" {
+				t.Errorf("Template.Execute result diff (-expect, +got)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestTemplate_ExecuteFullOutputURL(t *testing.T) {
+	t.Parallel()
+	templ := terraform.NewApplyTemplate("")
+
+	templ.SetValue(terraform.CommonTemplate{
+		Result:         "Apply complete! Resources: 1 added, 0 changed, 0 destroyed.",
+		CombinedOutput: "the full output",
+		FullOutputURL:  "https://gitlab.example.com/foo/bar/-/snippets/1",
+	})
+
+	got, err := templ.Execute()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expect := `
+## :white_check_mark: Apply Succeeded
+
+
+
+
+
+<pre><code>Apply complete! Resources: 1 added, 0 changed, 0 destroyed.</code></pre>
+
+:page_facing_up: [Full output](https://gitlab.example.com/foo/bar/-/snippets/1)
+`
+	if diff := cmp.Diff(expect, got); diff != "" {
+		t.Errorf("Template.Execute result diff (-expect, +got)\n%s", diff)
+	}
+}
+
+func TestTemplate_ExecuteAppliedResources(t *testing.T) {
+	t.Parallel()
+	templ := terraform.NewApplyTemplate(`{{template "applied_resources" .}}`)
+
+	templ.SetValue(terraform.CommonTemplate{
+		AppliedResources: []terraform.AppliedResource{
+			{Address: "aws_instance.foo", Action: terraform.ActionCreate, Duration: 3 * time.Second, ID: "i-1234"},
+			{Address: "aws_s3_bucket.bar", Action: terraform.ActionDelete, Failed: true, Error: "deleting S3 Bucket: BucketNotEmpty | retry"},
+		},
+	})
+
+	got, err := templ.Execute()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expect := "\n\n| Resource | Action | Duration | ID |\n" +
+		"|----------|--------|----------|----|\n" +
+		"| `aws_instance.foo` | create | 3s | i-1234 |\n" +
+		"| `aws_s3_bucket.bar` | :x: delete: deleting S3 Bucket: BucketNotEmpty \\| retry |  |  |"
+	if diff := cmp.Diff(expect, got); diff != "" {
+		t.Errorf("Template.Execute result diff (-expect, +got)\n%s", diff)
+	}
+}
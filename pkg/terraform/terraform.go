@@ -6,4 +6,7 @@ const (
 
 	// ExitFail is status code non-zero
 	ExitFail
+
+	// ExitChanges is status code of `terraform plan -detailed-exitcode` when the plan has changes
+	ExitChanges
 )
@@ -0,0 +1,156 @@
+package terraform
+
+import (
+	"os"
+	"regexp"
+	"strings"
+)
+
+// TerragruntPlanParser is a parser for the output of `terragrunt run-all plan`.
+// The output of the modules are interleaved and each line is prefixed with the path of the module.
+type TerragruntPlanParser struct {
+	// Prefixes match the lines of the modules. The first submatch is the path of the module and the second is the line
+	Prefixes []*regexp.Regexp
+	// ModulePrefix matches the lines prefixed with only the path of the module in the same way as Prefixes.
+	// The logs such as "[terragrunt] ..." and "[INFO] ..." are prefixed with brackets too,
+	// so the prefix is accepted only if it looks like the path of a module
+	ModulePrefix *regexp.Regexp
+	// Parser parses the output of each module
+	Parser Parser
+}
+
+// ModuleResult is the result of a module of terragrunt run-all
+type ModuleResult struct {
+	ParseResult
+	// Path is the path of the module
+	Path string
+	// Output is the output of the module without the prefixes
+	Output string
+}
+
+// NewTerragruntPlanParser is TerragruntPlanParser initializer
+func NewTerragruntPlanParser() *TerragruntPlanParser {
+	return &TerragruntPlanParser{
+		Prefixes: []*regexp.Regexp{
+			// e.g. "12:34:56.789 STDOUT [envs/prod/vpc] terraform: Plan: 1 to add, 0 to change, 0 to destroy."
+			regexp.MustCompile(`^(?:\d{2}:\d{2}:\d{2}(?:\.\d+)? +)?(?:STDOUT|STDERR) +\[(.+?)\] +(?:terraform|tofu): ?(.*)$`),
+		},
+		// e.g. "[envs/prod/vpc] Plan: 1 to add, 0 to change, 0 to destroy." with --terragrunt-include-module-prefix
+		ModulePrefix: regexp.MustCompile(`^\[([^\]\s]+)\] (.*)$`),
+		Parser:       NewPlanParser(),
+	}
+}
+
+type terragruntModule struct {
+	path  string
+	lines []string
+}
+
+// split splits the output into the outputs of the modules in the order of their first lines.
+// The lines which aren't prefixed, such as the logs of terragrunt, are ignored.
+func (p *TerragruntPlanParser) split(body string) []*terragruntModule {
+	var modules []*terragruntModule
+	index := map[string]*terragruntModule{}
+	for _, line := range strings.Split(body, "\n") {
+		path, text, ok := p.match(line)
+		if !ok {
+			continue
+		}
+		m, ok := index[path]
+		if !ok {
+			m = &terragruntModule{path: path}
+			index[path] = m
+			modules = append(modules, m)
+		}
+		m.lines = append(m.lines, text)
+	}
+	return modules
+}
+
+// match returns the path of the module and the line without the prefix
+func (p *TerragruntPlanParser) match(line string) (string, string, bool) {
+	for _, prefix := range p.Prefixes {
+		if arr := prefix.FindStringSubmatch(line); len(arr) == 3 { //nolint:gomnd
+			return arr[1], arr[2], true
+		}
+	}
+	if p.ModulePrefix == nil {
+		return "", "", false
+	}
+	arr := p.ModulePrefix.FindStringSubmatch(line)
+	if len(arr) != 3 || !isModulePath(arr[1]) { //nolint:gomnd
+		return "", "", false
+	}
+	return arr[1], arr[2], true
+}
+
+// isModulePath returns true if the prefix is a path such as envs/prod/vpc or an existing directory
+func isModulePath(path string) bool {
+	if path == "terragrunt" {
+		return false
+	}
+	if strings.Contains(path, "/") {
+		return true
+	}
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+// Parse returns ParseResult which combines the results of the modules.
+// Result lists the result of each module, and Modules has the result of each module.
+// If no line is prefixed with a module, the body is parsed as the output of a single module.
+func (p *TerragruntPlanParser) Parse(body string) ParseResult { //nolint:cyclop
+	modules := p.split(body)
+	if len(modules) == 0 {
+		return p.Parser.Parse(body)
+	}
+
+	combined := ParseResult{
+		HasNoChanges: true,
+		Modules:      make([]ModuleResult, len(modules)),
+	}
+	summaries := make([]string, len(modules))
+	var warnings []string
+	for i, m := range modules {
+		output := strings.Join(m.lines, "\n")
+		result := p.Parser.Parse(output)
+		combined.Modules[i] = ModuleResult{
+			ParseResult: result,
+			Path:        m.path,
+			Output:      output,
+		}
+
+		summary, _, _ := strings.Cut(result.Result, "\n")
+		if result.HasParseError {
+			summary = "It failed to parse the result."
+		}
+		summaries[i] = m.path + ": " + summary
+
+		combined.HasChanges = combined.HasChanges || result.HasChanges
+		combined.HasDestroy = combined.HasDestroy || result.HasDestroy
+		combined.HasPlanError = combined.HasPlanError || result.HasPlanError || result.HasParseError
+		combined.HasNoChanges = combined.HasNoChanges && result.HasNoChanges
+		if result.ExitCode > combined.ExitCode {
+			combined.ExitCode = result.ExitCode
+		}
+		if result.Warning != "" {
+			warnings = append(warnings, m.path+": "+result.Warning)
+		}
+		if combined.Tool == "" {
+			combined.Tool = result.Tool
+		}
+		combined.CreatedResources = append(combined.CreatedResources, result.CreatedResources...)
+		combined.UpdatedResources = append(combined.UpdatedResources, result.UpdatedResources...)
+		combined.DeletedResources = append(combined.DeletedResources, result.DeletedResources...)
+		combined.ReplacedResources = append(combined.ReplacedResources, result.ReplacedResources...)
+		combined.MovedResources = append(combined.MovedResources, result.MovedResources...)
+		combined.ImportedResources = append(combined.ImportedResources, result.ImportedResources...)
+		combined.ForgottenResources = append(combined.ForgottenResources, result.ForgottenResources...)
+		combined.ChangedOutputs = append(combined.ChangedOutputs, result.ChangedOutputs...)
+	}
+	combined.Result = strings.Join(summaries, "\n")
+	combined.Warning = strings.Join(warnings, "\n")
+	combined.HasNoChanges = combined.HasNoChanges && !combined.HasPlanError
+	combined.HasAddOrUpdateOnly = !combined.HasNoChanges && !combined.HasDestroy && !combined.HasPlanError
+	return combined
+}
@@ -0,0 +1,156 @@
+package terraform
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+const terragruntRunAllPlan = `12:00:00.000 INFO   The runner-pool runner at . will be processed in the following order for command plan:
+Group 1
+- Module ./envs/prod/network
+- Module ./envs/prod/db
+
+12:00:01.000 STDOUT [envs/prod/network] terraform: Terraform will perform the following actions:
+12:00:01.000 STDOUT [envs/prod/db] terraform: No changes. Your infrastructure matches the configuration.
+12:00:01.000 STDOUT [envs/prod/network] terraform:   # aws_subnet.private will be destroyed
+12:00:01.000 STDOUT [envs/prod/network] terraform:   - resource "aws_subnet" "private" {
+12:00:01.000 STDOUT [envs/prod/network] terraform:     }
+12:00:01.000 STDOUT [envs/prod/network] terraform:   # aws_vpc.main will be created
+12:00:01.000 STDOUT [envs/prod/network] terraform:   + resource "aws_vpc" "main" {
+12:00:01.000 STDOUT [envs/prod/network] terraform:     }
+12:00:01.000 STDOUT [envs/prod/network] terraform: Plan: 1 to add, 0 to change, 1 to destroy.
+`
+
+func TestTerragruntPlanParserParse(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		name   string
+		body   string
+		result ParseResult
+	}{
+		{
+			name: "run-all plan",
+			body: terragruntRunAllPlan,
+			result: ParseResult{
+				Result:           "envs/prod/network: Plan: 1 to add, 0 to change, 1 to destroy.\nenvs/prod/db: No changes. Your infrastructure matches the configuration.",
+				HasChanges:       true,
+				HasDestroy:       true,
+				Tool:             ToolTerraform,
+				CreatedResources: []string{"aws_vpc.main"},
+				DeletedResources: []string{"aws_subnet.private"},
+				Modules: []ModuleResult{
+					{
+						Path: "envs/prod/network",
+						Output: `Terraform will perform the following actions:
+  # aws_subnet.private will be destroyed
+  - resource "aws_subnet" "private" {
+    }
+  # aws_vpc.main will be created
+  + resource "aws_vpc" "main" {
+    }
+Plan: 1 to add, 0 to change, 1 to destroy.`,
+						ParseResult: ParseResult{
+							Result: "Plan: 1 to add, 0 to change, 1 to destroy.",
+							ChangedResult: `  # aws_subnet.private will be destroyed
+  - resource "aws_subnet" "private" {
+    }
+  # aws_vpc.main will be created
+  + resource "aws_vpc" "main" {
+    }
+Plan: 1 to add, 0 to change, 1 to destroy.`,
+							HasChanges:       true,
+							HasDestroy:       true,
+							Tool:             ToolTerraform,
+							CreatedResources: []string{"aws_vpc.main"},
+							DeletedResources: []string{"aws_subnet.private"},
+						},
+					},
+					{
+						Path:   "envs/prod/db",
+						Output: "No changes. Your infrastructure matches the configuration.",
+						ParseResult: ParseResult{
+							Result:       "No changes. Your infrastructure matches the configuration.",
+							HasNoChanges: true,
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "module prefix with a plan error",
+			body: `[envs/dev] Error: Invalid reference
+[envs/stg] Plan: 1 to add, 0 to change, 0 to destroy.`,
+			result: ParseResult{
+				Result:       "envs/dev: Error: Invalid reference\nenvs/stg: Plan: 1 to add, 0 to change, 0 to destroy.",
+				HasChanges:   true,
+				HasPlanError: true,
+				ExitCode:     ExitFail,
+				Modules: []ModuleResult{
+					{
+						Path:   "envs/dev",
+						Output: "Error: Invalid reference",
+						ParseResult: ParseResult{
+							Result:       "Error: Invalid reference",
+							HasPlanError: true,
+							ExitCode:     ExitFail,
+						},
+					},
+					{
+						Path:   "envs/stg",
+						Output: "Plan: 1 to add, 0 to change, 0 to destroy.",
+						ParseResult: ParseResult{
+							Result:             "Plan: 1 to add, 0 to change, 0 to destroy.",
+							HasAddOrUpdateOnly: true,
+							HasChanges:         true,
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "module prefix mixed with log lines",
+			body: `[terragrunt] 2024/01/01 12:00:00 Running command: terraform plan
+[terragrunt] [envs/dev] 2024/01/01 12:00:00 Executing hook: tflint
+[INFO] Getting version from tgenv-version-name
+[envs/dev] Plan: 1 to add, 0 to change, 0 to destroy.
+[WARN] The module has been deprecated`,
+			result: ParseResult{
+				Result:             "envs/dev: Plan: 1 to add, 0 to change, 0 to destroy.",
+				HasAddOrUpdateOnly: true,
+				HasChanges:         true,
+				Modules: []ModuleResult{
+					{
+						Path:   "envs/dev",
+						Output: "Plan: 1 to add, 0 to change, 0 to destroy.",
+						ParseResult: ParseResult{
+							Result:             "Plan: 1 to add, 0 to change, 0 to destroy.",
+							HasAddOrUpdateOnly: true,
+							HasChanges:         true,
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "output of a single module",
+			body: "Plan: 1 to add, 0 to change, 0 to destroy.",
+			result: ParseResult{
+				Result:             "Plan: 1 to add, 0 to change, 0 to destroy.",
+				HasAddOrUpdateOnly: true,
+				HasChanges:         true,
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+			result := NewTerragruntPlanParser().Parse(testCase.body)
+			if diff := cmp.Diff(testCase.result, result, cmpopts.IgnoreFields(ParseResult{}, "Error")); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package terraform
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// The tools which run plan and apply
+const (
+	ToolTerraform = "terraform"
+	ToolOpenTofu  = "opentofu"
+)
+
+// toolOutput matches the lines which only the tool outputs. The submatch is the name of the tool
+var toolOutput = regexp.MustCompile(`(?m)^(?:(Terraform|OpenTofu) (?:will perform the following actions:|used the selected providers|has compared your real infrastructure|has checked that the real remote objects|v\d)|Note: Objects have changed outside of (Terraform|OpenTofu)$)`)
+
+// ToolName returns the name of the tool in the output such as Terraform and OpenTofu.
+// If the tool is empty, Terraform is returned
+func ToolName(tool string) string {
+	if tool == ToolOpenTofu {
+		return "OpenTofu"
+	}
+	return "Terraform"
+}
+
+// ResolveToolName returns the name of the tool which outputs the result.
+// The configured tool takes precedence over the tool detected from the output
+func ResolveToolName(tool string, result ParseResult) string {
+	if tool == "" {
+		tool = result.Tool
+	}
+	return ToolName(tool)
+}
+
+// ToolCommand returns the command of the tool such as terraform and tofu
+func ToolCommand(tool string) string {
+	if tool == ToolOpenTofu {
+		return "tofu"
+	}
+	return "terraform"
+}
+
+// DetectTool returns the tool which the command runs. Terragrunt is resolved by the environment variables
+// which choose the binary. If the tool is unknown, an empty string is returned.
+func DetectTool(command string) string {
+	switch strings.TrimSuffix(filepath.Base(command), ".exe") {
+	case "tofu":
+		return ToolOpenTofu
+	case "terraform":
+		return ToolTerraform
+	case "terragrunt":
+		for _, env := range []string{"TG_TF_PATH", "TERRAGRUNT_TFPATH"} {
+			if p := os.Getenv(env); p != "" {
+				return DetectTool(p)
+			}
+		}
+	}
+	return ""
+}
+
+// detectToolFromOutput returns the tool which outputs the body. If the tool is unknown, an empty string is returned
+func detectToolFromOutput(body string) string {
+	arr := toolOutput.FindStringSubmatch(body)
+	if arr == nil {
+		return ""
+	}
+	if arr[1] == "OpenTofu" || arr[2] == "OpenTofu" {
+		return ToolOpenTofu
+	}
+	return ToolTerraform
+}
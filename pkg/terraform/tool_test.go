@@ -0,0 +1,91 @@
+package terraform
+
+import (
+	"testing"
+)
+
+func TestDetectTool(t *testing.T) { //nolint:paralleltest
+	testCases := []struct {
+		name    string
+		command string
+		env     map[string]string
+		exp     string
+	}{
+		{
+			name:    "terraform",
+			command: "terraform",
+			exp:     ToolTerraform,
+		},
+		{
+			name:    "tofu with a path",
+			command: "/usr/local/bin/tofu",
+			exp:     ToolOpenTofu,
+		},
+		{
+			name:    "terragrunt with tofu",
+			command: "terragrunt",
+			env:     map[string]string{"TG_TF_PATH": "tofu"},
+			exp:     ToolOpenTofu,
+		},
+		{
+			name:    "terragrunt without the binary",
+			command: "terragrunt",
+			exp:     "",
+		},
+		{
+			name:    "unknown",
+			command: "make",
+			exp:     "",
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Setenv("TG_TF_PATH", "")
+			t.Setenv("TERRAGRUNT_TFPATH", "")
+			for k, v := range testCase.env {
+				t.Setenv(k, v)
+			}
+			if tool := DetectTool(testCase.command); tool != testCase.exp {
+				t.Errorf("got %q but want %q", tool, testCase.exp)
+			}
+		})
+	}
+}
+
+func TestTemplateTool(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		name string
+		tool string
+		exp  string
+	}{
+		{
+			name: "terraform keeps the title",
+			tool: "Terraform",
+			exp:  "## Plan Result (foo)",
+		},
+		{
+			name: "opentofu",
+			tool: "OpenTofu",
+			exp:  "## OpenTofu Plan Result (foo)",
+		},
+	}
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+			tpl := NewPlanTemplate(`{{template "plan_title" .}}`)
+			tpl.SetValue(CommonTemplate{
+				Vars: map[string]string{"target": "foo"},
+				Tool: testCase.tool,
+			})
+			body, err := tpl.Execute()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if body != testCase.exp {
+				t.Errorf("got %q but want %q", body, testCase.exp)
+			}
+		})
+	}
+}